@@ -0,0 +1,257 @@
+package dtdiff
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateOnly matches inputs that contain a date but no time of day
+const dateOnly string = `^(\d{4}([-/.]\d{1,2}([-/.]\d{1,2})?)?|\d{8}|\d{1,2}/\d{1,2}/\d{4})$`
+
+var dateOnlyRegexp = regexp.MustCompile(dateOnly)
+
+// part is one component of a duration, such as "3 days" or "1.5 hours"
+type part struct {
+	amount int64
+	unit   string
+	// the decimal portion of the amount, such as 0.5 for "1.5 hours"; it has the same sign as amount
+	fraction float64
+}
+
+// clockUnits are the fixed length units used below a day, from the largest to the smallest
+var clockUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+	{"millisecond", time.Millisecond},
+	{"microsecond", time.Microsecond},
+	{"nanosecond", time.Nanosecond},
+}
+
+// isDateOnly return true when value is a date without a time of day, such as "2024-01-31"
+func isDateOnly(value string) bool {
+	return dateOnlyRegexp.MatchString(strings.TrimSpace(value))
+}
+
+// calendarBreakdown walk the calendar from start to end and return the years, months, days
+// and clock units between them; the units are applied in the same order and with the same
+// month overflow rules as Add, so Add(start, result) == end; when end is before start,
+// Sub(start, result) == end and negative is true
+func calendarBreakdown(start, end time.Time) (parts []part, negative bool) {
+	end = end.In(start.Location())
+	sign := 1
+	if end.Before(start) {
+		sign = -1
+		negative = true
+	}
+	// reached return true when t has not gone past end in the direction of travel
+	reached := func(t time.Time) bool {
+		if sign == 1 {
+			return !t.After(end)
+		}
+		return !t.Before(end)
+	}
+
+	// estimate each calendar unit from the dates, then correct the estimate by walking
+	anchor := start
+	years := sign * (end.Year() - start.Year())
+	for years > 0 && !reached(anchor.AddDate(sign*years, 0, 0)) {
+		years--
+	}
+	anchor = anchor.AddDate(sign*years, 0, 0)
+
+	months := sign * ((end.Year()-anchor.Year())*12 + int(end.Month()) - int(anchor.Month()))
+	for months > 0 && !reached(anchor.AddDate(0, sign*months, 0)) {
+		months--
+	}
+	anchor = anchor.AddDate(0, sign*months, 0)
+
+	days := sign * int(end.Sub(anchor).Hours()/24)
+	for days > 0 && !reached(anchor.AddDate(0, 0, sign*days)) {
+		days--
+	}
+	for reached(anchor.AddDate(0, 0, sign*(days+1))) {
+		days++
+	}
+	anchor = anchor.AddDate(0, 0, sign*days)
+
+	parts = append(parts, part{amount: int64(years), unit: "year"}, part{amount: int64(months), unit: "month"}, part{amount: int64(days), unit: "day"})
+	return append(parts, clockParts(end.Sub(anchor)*time.Duration(sign))...), negative
+}
+
+// breakdownUnits are the units accepted by WithUnits, from the largest to the smallest
+var breakdownUnits = []string{"year", "month", "week", "day", "hour", "minute", "second", "millisecond", "microsecond", "nanosecond"}
+
+// unitBreakdown return the difference from start to end using only the allowed units, from the largest
+// to the smallest; years, months, weeks and days walk the calendar the same way as calendarBreakdown
+// and the clock units use their fixed length; anything smaller than the smallest unit is dropped
+func unitBreakdown(start, end time.Time, allowed map[string]bool) (parts []part, negative bool) {
+	end = end.In(start.Location())
+	sign := 1
+	if end.Before(start) {
+		sign = -1
+		negative = true
+	}
+	reached := func(t time.Time) bool {
+		if sign == 1 {
+			return !t.After(end)
+		}
+		return !t.Before(end)
+	}
+
+	anchor := start
+	// the nanoseconds left for the clock units, which follow the calendar units
+	var rest *big.Int
+	for _, unit := range breakdownUnits {
+		if !allowed[unit] {
+			continue
+		}
+		size, fixed := fixedUnits[unit]
+		if fixed && unit != "week" && unit != "day" {
+			if rest == nil {
+				rest = exactDiff(anchor, end)
+				rest.Abs(rest)
+			}
+			n, r := new(big.Int).QuoRem(rest, big.NewInt(int64(size)), new(big.Int))
+			rest = r
+			parts = append(parts, part{amount: n.Int64(), unit: unit})
+			continue
+		}
+
+		// estimate the amount, then correct the estimate by walking
+		var n int
+		switch unit {
+		case "year":
+			n = sign * (end.Year() - anchor.Year())
+		case "month":
+			n = sign * ((end.Year()-anchor.Year())*12 + int(end.Month()) - int(anchor.Month()))
+		default:
+			days := exactDiff(anchor, end)
+			n = int(days.Abs(days).Quo(days, big.NewInt(int64(size))).Int64())
+		}
+		for n > 0 && !reached(addCalendarUnit(anchor, unit, sign*n)) {
+			n--
+		}
+		for reached(addCalendarUnit(anchor, unit, sign*(n+1))) {
+			n++
+		}
+		anchor = addCalendarUnit(anchor, unit, sign*n)
+		parts = append(parts, part{amount: int64(n), unit: unit})
+	}
+	return parts, negative
+}
+
+// unitsOverflow return the unit whose amount in unitBreakdown does not fit in an int64, or "" when they all fit
+// this can only happen to the largest allowed unit when it is a clock unit, such as the nanoseconds of more than 292 years
+func unitsOverflow(start, end time.Time, allowed map[string]bool) string {
+	for _, unit := range breakdownUnits {
+		if !allowed[unit] {
+			continue
+		}
+		size, fixed := fixedUnits[unit]
+		if !fixed || unit == "week" || unit == "day" {
+			return ""
+		}
+		if n := exactDiff(start, end); !n.Quo(n, big.NewInt(int64(size))).IsInt64() {
+			return unit
+		}
+		return ""
+	}
+	return ""
+}
+
+// addCalendarUnit return t moved by n years, months, weeks or days
+func addCalendarUnit(t time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "year":
+		return t.AddDate(n, 0, 0)
+	case "month":
+		return t.AddDate(0, n, 0)
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// clockParts split a non-negative duration into hours, minutes, seconds and sub-second units
+func clockParts(rest time.Duration) []part {
+	var parts []part
+	for _, u := range clockUnits {
+		parts = append(parts, part{amount: int64(rest / u.size), unit: u.name})
+		rest %= u.size
+	}
+	return parts
+}
+
+// formatParts return the non-zero parts in long format, such as "1 month 2 days" or "1 month -2 days"
+// every part is negated when negative is true, such as "-1 month -2 days", so that the result can be parsed back
+func formatParts(parts []part, negative bool) string {
+	var words []string
+	for _, p := range parts {
+		if p.amount == 0 && p.fraction == 0 {
+			continue
+		}
+		if negative {
+			p = part{amount: -p.amount, unit: p.unit, fraction: -p.fraction}
+		}
+		unit := p.unit
+		if unit == businessDay {
+			unit = "business day"
+		}
+		if p.fraction != 0 || (p.amount != 1 && p.amount != -1) {
+			unit += "s"
+		}
+		words = append(words, fmt.Sprintf("%s %s", p.formatAmount(), unit))
+	}
+	if len(words) == 0 {
+		return formatZero("second")
+	}
+	return strings.Join(words, " ")
+}
+
+// formatZero return a zero amount of unit in long format, such as "0 seconds"
+func formatZero(unit string) string {
+	return "0 " + unit + "s"
+}
+
+// isZero return true when every one of the parts is zero
+func isZero(parts []part) bool {
+	for _, p := range parts {
+		if p.amount != 0 || p.fraction != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// formatAmount return the amount of p, including any decimal portion, such as "-1.5"
+func (p part) formatAmount() string {
+	if p.fraction == 0 {
+		return strconv.FormatInt(p.amount, 10)
+	}
+	return strconv.FormatFloat(float64(p.amount)+p.fraction, 'f', -1, 64)
+}
+
+// normalize carry any whole number out of the fraction, so that the fraction
+// is less than one and has the same sign as the amount
+func (p part) normalize() part {
+	whole := math.Trunc(p.fraction)
+	p.amount += int64(whole)
+	p.fraction -= whole
+	if p.amount > 0 && p.fraction < 0 {
+		p.amount--
+		p.fraction++
+	} else if p.amount < 0 && p.fraction > 0 {
+		p.amount++
+		p.fraction--
+	}
+	return p
+}
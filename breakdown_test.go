@@ -0,0 +1,216 @@
+package dtdiff
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarDiffDateOnly(t *testing.T) {
+	allStarts := []string{"2024-01-31", "2024-01-01", "2023-02-28", "2024-03-01", "2020-02-29"}
+	allEnds := []string{"2024-03-01", "2025-12-31", "2024-03-01", "2024-01-31", "2024-02-28"}
+	allCorrect := []string{"30 days", "1 year 11 months 30 days", "1 year 2 days", "-1 month -1 day", "3 years 11 months 27 days"}
+	for i := range allStarts {
+		testStartEnd(t, allStarts[i], allEnds[i], allCorrect[i])
+	}
+}
+
+func TestCalendarDiffOptIn(t *testing.T) {
+	dt := New("2024-01-31 08:00:00", "2024-03-03 09:30:00.5")
+	dt.SetCalendarDiff(true)
+	format, _, err := dt.DtDiff()
+	if err != nil {
+		t.Error(err)
+	}
+	correct := "1 month 1 day 1 hour 30 minutes 500 milliseconds"
+	if format != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", format, correct)
+	}
+
+	dt.SetBrief(true)
+	format, _, _ = dt.DtDiff()
+	if format != "1M1D1h30m500ms" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "1M1D1h30m500ms")
+	}
+
+	dt = New("2024-01-01", "2024-03-01")
+	dt.SetCalendarDiff(false)
+	format, _, _ = dt.DtDiff()
+	if format != "8 weeks 4 days" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "8 weeks 4 days")
+	}
+}
+
+// TestCalendarDiffRoundTrip ensure that adding the breakdown to start returns end
+func TestCalendarDiffRoundTrip(t *testing.T) {
+	allStarts := []string{"2024-01-31 23:59:59", "2023-12-31 12:00:00", "2024-02-29 00:00:00", "2025-07-15 06:07:08.123456789"}
+	allEnds := []string{"2024-03-01 00:00:01", "2028-02-29 11:59:59", "2025-02-28 23:00:00", "2024-01-31 10:00:00"}
+	for i := range allStarts {
+		dt := New(allStarts[i], allEnds[i])
+		dt.SetCalendarDiff(true)
+		dt.SetBrief(true)
+		format, _, err := dt.DtDiff()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		// a negative breakdown has a minus sign on every unit, so it is added as well
+		computed, err := Add(allStarts[i], format)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.HasPrefix(computed, allEnds[i]) {
+			t.Errorf("[start: %v] [period: %v] [computed: %v] != [correct: %v]", allStarts[i], format, computed, allEnds[i])
+		}
+	}
+}
+
+// TestNegativeRoundTrip ensure that every unit of a negative difference is signed, so that adding it returns end
+func TestNegativeRoundTrip(t *testing.T) {
+	allStarts := []string{"2024-03-01", "2024-01-10 10:30:00", "2024-01-10 10:30:00"}
+	allEnds := []string{"2024-01-31", "2024-01-01 09:00:00", "2024-01-01 09:00:00"}
+	allBrief := []bool{false, false, true}
+	allCorrect := []string{"-1 month -1 day", "-1 week -2 days -1 hour -30 minutes", "-1W-2D-1h-30m"}
+	for i := range allStarts {
+		dt := New(allStarts[i], allEnds[i])
+		dt.SetBrief(allBrief[i])
+		format, _, err := dt.DtDiff()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if format != allCorrect[i] {
+			t.Errorf("[start: %v] [end: %v] [computed: %v] != [correct: %v]", allStarts[i], allEnds[i], format, allCorrect[i])
+		}
+		computed, err := Add(allStarts[i], format)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.HasPrefix(computed, allEnds[i]) {
+			t.Errorf("[start: %v] [period: %v] [computed: %v] != [correct: %v]", allStarts[i], format, computed, allEnds[i])
+		}
+	}
+}
+
+func TestCalendarBreakdownDST(t *testing.T) {
+	berlin, err := LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 3, 30, 12, 0, 0, 0, berlin)
+	end := time.Date(2024, 4, 1, 12, 0, 0, 0, berlin)
+	format := formatParts(calendarBreakdown(start, end))
+	if format != "2 days" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "2 days")
+	}
+}
+
+func TestIsDateOnly(t *testing.T) {
+	for _, value := range []string{"2024-01-31", "2024/1/2", "20240131", "1/31/2024", "2024"} {
+		if !isDateOnly(value) {
+			t.Errorf("[value: %v] should be date only", value)
+		}
+	}
+	for _, value := range []string{"2024-01-31 00:00:00", "2024-01-31T00:00:00Z", "11:00AM", "now"} {
+		if isDateOnly(value) {
+			t.Errorf("[value: %v] should not be date only", value)
+		}
+	}
+}
+
+func TestWithUnits(t *testing.T) {
+	tests := []struct {
+		start   string
+		end     string
+		units   []string
+		correct string
+	}{
+		{"2024-01-01T00:00:00Z", "2024-01-18T02:00:00Z", []string{"days", "hours"}, "17 days 2 hours"},
+		{"2024-01-01T00:00:00Z", "2024-01-18T02:30:45Z", []string{"h"}, "410 hours"},
+		{"2024-01-31", "2024-03-03", []string{"month", "days"}, "1 month 1 day"},
+		{"2024-01-31", "2025-03-03", []string{"months", "weeks"}, "13 months"},
+		{"2024-03-03", "2024-01-31", []string{"days"}, "-32 days"},
+		{"2024-01-01T00:00:00Z", "2024-01-01T00:00:01.5Z", []string{"ms"}, "1500 milliseconds"},
+	}
+	for _, test := range tests {
+		dt, err := NewWithOptions(test.start, test.end, WithUnits(test.units...))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		format, _, err := dt.DtDiff()
+		if err != nil {
+			t.Error(err)
+		}
+		if format != test.correct {
+			t.Errorf("[units: %v] [computed: %v] != [correct: %v]", test.units, format, test.correct)
+		}
+	}
+
+	if _, err := NewWithOptions("2024-01-01", "2024-01-02", WithUnits("days", "fortnights")); err == nil {
+		t.Errorf("WithUnits should fail for an unknown unit")
+	}
+	if _, err := NewWithOptions("2024-01-01", "2024-01-02", WithUnits("BD")); err == nil {
+		t.Errorf("WithUnits should fail for business days")
+	}
+}
+
+func TestUnitRange(t *testing.T) {
+	tests := []struct {
+		start   string
+		end     string
+		opts    []Option
+		correct string
+	}{
+		{"2024-01-01T00:00:00Z", "2024-01-18T02:00:00.5Z", []Option{WithMaxUnit("days")}, "17 days 2 hours"},
+		{"2024-01-01T00:00:00Z", "2024-01-18T02:30:45Z", []Option{WithMaxUnit("D"), WithMinUnit("hours")}, "17 days 2 hours"},
+		{"2024-01-01T00:00:00Z", "2024-01-01T00:00:01.5Z", []Option{WithMaxUnit("s"), WithMinUnit("ms")}, "1 second 500 milliseconds"},
+		{"2024-01-01T00:00:00Z", "2025-02-18T02:30:45Z", []Option{WithMinUnit("day")}, "1 year 1 month 2 weeks 3 days"},
+		{"2024-01-01T00:00:00Z", "2024-01-18T02:30:45.5Z", []Option{WithUnits("weeks", "hours", "ms"), WithMaxUnit("days")}, "410 hours 1845500 milliseconds"},
+		{"2024-01-01T00:00:00Z", "2024-01-01T00:00:30Z", []Option{WithUnits("days", "hours")}, "0 hours"},
+		{"2024-01-01T00:00:00Z", "2024-01-01T00:30:00Z", []Option{WithMinUnit("hours")}, "0 hours"},
+		{"2024-01-01T00:00:00Z", "2024-01-01T00:00:30Z", []Option{WithUnits("hours"), WithBrief()}, "0h"},
+		{"2024-01-01T00:00:00Z", "2024-01-01T00:00:30Z", []Option{WithUnits("days"), WithLocale("de")}, "0 Tage"},
+		{"1600-01-01T00:00:00Z", "2024-01-01T00:00:30Z", []Option{WithUnits("hours")}, "3716712 hours"},
+		{"2024-01-01T00:00:30Z", "1600-01-01T00:00:00Z", []Option{WithUnits("weeks", "hours", "seconds")}, "-22123 weeks -48 hours -30 seconds"},
+		{"1600-01-01T00:00:00Z", "2024-01-01T00:00:30Z", []Option{WithUnits("days", "nanoseconds")}, "154863 days 30000000000 nanoseconds"},
+	}
+	for _, test := range tests {
+		dt, err := NewWithOptions(test.start, test.end, test.opts...)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		format, _, err := dt.DtDiff()
+		if err != nil {
+			t.Error(err)
+		}
+		if format != test.correct {
+			t.Errorf("[start: %v] [end: %v] [computed: %v] != [correct: %v]", test.start, test.end, format, test.correct)
+		}
+	}
+
+	if _, err := NewWithOptions("2024-01-01", "2024-01-02", WithMaxUnit("hours"), WithMinUnit("days")); err == nil {
+		t.Errorf("WithMaxUnit should fail when it is smaller than WithMinUnit")
+	}
+	if _, err := NewWithOptions("2024-01-01", "2024-01-02", WithUnits("years"), WithMaxUnit("days")); err == nil {
+		t.Errorf("WithMaxUnit should fail when none of the units are left")
+	}
+	if _, err := NewWithOptions("2024-01-01", "2024-01-02", WithMinUnit("fortnights")); err == nil {
+		t.Errorf("WithMinUnit should fail for an unknown unit")
+	}
+	dt, _ := NewWithOptions("1600-01-01T00:00:00Z", "2024-01-01T00:00:00Z", WithUnits("nanoseconds"))
+	if _, _, err := dt.DtDiff(); !errors.Is(err, ErrRangeOverflow) {
+		t.Errorf("[computed: %v] is not: [correct: %v]", err, ErrRangeOverflow)
+	}
+	if err := ValidateOptions(WithMaxUnit("hours"), WithMinUnit("days")); err == nil {
+		t.Errorf("ValidateOptions should fail when WithMaxUnit is smaller than WithMinUnit")
+	}
+	if err := ValidateOptions(WithUnits("days", "hours"), WithStrftime("%Y-%m-%d")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,144 @@
+package dtdiff
+
+import (
+	"fmt"
+	"github.com/golang-module/carbon/v2"
+	"strings"
+	"time"
+)
+
+var defaultWeekend = []time.Weekday{time.Saturday, time.Sunday}
+
+// isWeekend return true when t falls on one of the "weekend" days
+func isWeekend(t time.Time, weekend []time.Weekday) bool {
+	for _, day := range weekend {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+	return false
+}
+
+// addBusinessDays move "to" forward (index==0) or backward (index==1), only counting days that are
+// neither weekends nor holidays, until "num" business days have passed; whole weeks are skipped at once
+// and the holidays within them are made up afterwards, one day at a time
+// the time of day is preserved; starting on a weekend is allowed, Saturday + 1 business day is Monday
+func addBusinessDays(to carbon.Carbon, num, index int, o options) (carbon.Carbon, error) {
+	cal := o.businessCalendar()
+	if !hasBusinessDay(cal.Weekend) {
+		return to, weekendError("addBusinessDays", cal.Weekend)
+	}
+
+	step := 1
+	if index == 1 {
+		step = -1
+	}
+	t := to.StdTime()
+	perWeek := businessDaysPerWeek(cal.Weekend)
+	for num > perWeek {
+		weeks := (num - 1) / perWeek
+		next := t.AddDate(0, 0, step*7*weeks)
+		num += cal.holidaysBetween(t, next) - weeks*perWeek
+		t = next
+	}
+	for num > 0 {
+		t = t.AddDate(0, 0, step)
+		if cal.IsBusinessDay(t) {
+			num--
+		}
+	}
+	return carbon.CreateFromStdTime(t), nil
+}
+
+// businessDaysPerWeek return the number of days of the week which are not part of the weekend
+func businessDaysPerWeek(weekend []time.Weekday) int {
+	seen := make(map[time.Weekday]bool)
+	for _, day := range weekend {
+		seen[day] = true
+	}
+	return 7 - len(seen)
+}
+
+// holidaysBetween return the number of holidays which are not on a weekend, after the date of "from"
+// up to and including the date of "to"; when "to" is before "from", the dates from "to" up to "from" are used
+func (c *Calendar) holidaysBetween(from, to time.Time) int {
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	forward := !last.Before(first)
+	count := 0
+	for date := range c.Holidays {
+		day, err := time.Parse(holidayLayout, date)
+		if err != nil || isWeekend(day, c.Weekend) {
+			continue
+		}
+		if (forward && day.After(first) && !day.After(last)) || (!forward && !day.Before(last) && day.Before(first)) {
+			count++
+		}
+	}
+	return count
+}
+
+// countBusinessDays return the number of business days between start and end
+// this is the inverse of addBusinessDays: days in (start, end] are counted when moving forward
+// and days in [end, start) are counted, as a negative number, when moving backward
+func countBusinessDays(start, end time.Time, cal *Calendar) int {
+	step, sign := 1, 1
+	if end.Before(start) {
+		step, sign = -1, -1
+	}
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, start.Location())
+
+	count := 0
+	for day := first; !day.Equal(last); day = day.AddDate(0, 0, step) {
+		if cal.IsBusinessDay(day.AddDate(0, 0, step)) {
+			count++
+		}
+	}
+	return sign * count
+}
+
+// hasBusinessDay return true when at least one day of the week is not part of the weekend
+func hasBusinessDay(weekend []time.Weekday) bool {
+	return businessDaysPerWeek(weekend) > 0
+}
+
+// weekendError return an ErrInvalidCalendar for a weekend without any business days
+func weekendError(caller string, weekend []time.Weekday) error {
+	names := make([]string, len(weekend))
+	for i, day := range weekend {
+		names[i] = strings.ToLower(day.String()[:3])
+	}
+	return calendarError(strings.Join(names, ","), fmt.Sprintf("[%s] Invalid weekend: every day of the week is a weekend day", caller))
+}
+
+// ParseWeekend convert a comma-separated list of day names, such as "fri,sat"
+// or "Saturday,Sunday", into weekdays that can be given to WithWeekend
+func ParseWeekend(days string) ([]time.Weekday, error) {
+	var weekend []time.Weekday
+	for _, name := range strings.Split(days, ",") {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return nil, calendarError(days, fmt.Sprintf("[ParseWeekend] Invalid day: %s", strings.ToLower(strings.TrimSpace(name))))
+		}
+		weekend = append(weekend, day)
+	}
+	if !hasBusinessDay(weekend) {
+		return nil, weekendError("ParseWeekend", weekend)
+	}
+	return weekend, nil
+}
+
+// parseWeekday convert a day name, or the first three or more letters of one, into a weekday
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 3 {
+		return time.Sunday, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.HasPrefix(strings.ToLower(day.String()), name) {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
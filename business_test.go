@@ -0,0 +1,133 @@
+package dtdiff
+
+import (
+	"github.com/golang-module/carbon/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBusinessDays(t *testing.T) {
+	from := "2024-06-14 09:00:00" // a Friday
+	period := "10 business days"
+	briefPeriod := "10BD"
+	correctAdd := "2024-06-28 09:00:00"
+	correctSub := "2024-05-31 09:00:00"
+	testAddSubContains(t, from, period, correctAdd, correctSub)
+	testAddSubContains(t, from, briefPeriod, correctAdd, correctSub)
+}
+
+func TestBusinessDaysFromWeekend(t *testing.T) {
+	from := "2024-06-15" // a Saturday
+	period := "1BD"
+	correctAdd := "2024-06-17"
+	correctSub := "2024-06-14"
+	testAddSubContains(t, from, period, correctAdd, correctSub)
+}
+
+func TestBusinessDaysMixedUnits(t *testing.T) {
+	from := "2024-06-14"
+	period := "1W2BD3h"
+	correctAdd := "2024-06-25 03:00:00"
+	correctSub := "2024-06-04 21:00:00"
+	testAddSubContains(t, from, period, correctAdd, correctSub)
+}
+
+func TestBusinessDaysCustomWeekend(t *testing.T) {
+	weekend, err := ParseWeekend("fri,sat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	future, err := Add("2024-06-13", "2BD", WithWeekend(weekend...))
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(future, "2024-06-17") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v]", future, "2024-06-17")
+	}
+
+	all, err := AddWithRecurrence("2024-06-13", "1BD", 3, WithWeekend(weekend...))
+	if err != nil {
+		t.Error(err)
+	}
+	allCorrect := []string{"2024-06-16", "2024-06-17", "2024-06-18"}
+	for i := range all {
+		if !strings.Contains(all[i], allCorrect[i]) {
+			t.Errorf("[computed: %v] does not contain: [correct: %v]", all[i], allCorrect[i])
+		}
+	}
+
+	all, err = AddUntil("2024-06-13", "2024-06-20", "2BD", WithWeekend(weekend...))
+	if err != nil {
+		t.Error(err)
+	}
+	allCorrect = []string{"2024-06-17", "2024-06-19"}
+	if len(all) != len(allCorrect) {
+		t.Fatalf("[computed: %v] != [correct: %v]", all, allCorrect)
+	}
+	for i := range all {
+		if !strings.Contains(all[i], allCorrect[i]) {
+			t.Errorf("[computed: %v] does not contain: [correct: %v]", all[i], allCorrect[i])
+		}
+	}
+}
+
+func TestBusinessDaysNoWorkdays(t *testing.T) {
+	allDays := []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+	_, err := Add("2024-06-13", "1BD", WithWeekend(allDays...))
+	if err == nil {
+		t.Error("expected an error when every day is a weekend day")
+	}
+}
+
+func TestParseWeekend(t *testing.T) {
+	weekend, err := ParseWeekend("Friday, sat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(weekend) != 2 || weekend[0] != time.Friday || weekend[1] != time.Saturday {
+		t.Errorf("[computed: %v] != [correct: %v]", weekend, []time.Weekday{time.Friday, time.Saturday})
+	}
+	for _, days := range []string{"fr", "funday", ""} {
+		if _, err := ParseWeekend(days); err == nil {
+			t.Errorf("[days: %v] expected an error", days)
+		}
+	}
+}
+
+func TestBusinessDaysSkipWeeks(t *testing.T) {
+	cal := NewCalendar(time.Friday, time.Saturday)
+	start := time.Date(2024, 6, 13, 9, 0, 0, 0, time.UTC)
+	for _, date := range []string{"2024-06-17", "2024-06-21", "2024-07-04", "2024-12-25", "2025-01-01", "2023-12-25", "2024-05-27"} {
+		day, _ := time.Parse(holidayLayout, date)
+		cal.AddHoliday(day, "holiday")
+	}
+	for _, n := range []int{1, 4, 5, 6, 11, 150, 400} {
+		for index, step := range []int{1, -1} {
+			// walk one day at a time
+			correct := start
+			for left := n; left > 0; {
+				correct = correct.AddDate(0, 0, step)
+				if cal.IsBusinessDay(correct) {
+					left--
+				}
+			}
+			computed, err := addBusinessDays(carbon.CreateFromStdTime(start), n, index, options{calendar: cal})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !computed.StdTime().Equal(correct) {
+				t.Errorf("[n: %v] [index: %v] [computed: %v] != [correct: %v]", n, index, computed.StdTime(), correct)
+			}
+		}
+	}
+
+	// a large amount must finish
+	future, err := AddTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Period{BusinessDays: 99999999999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if future.Year() < 380000000 {
+		t.Errorf("[computed: %v] is too early", future)
+	}
+}
@@ -0,0 +1,384 @@
+package dtdiff
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const holidayLayout string = "2006-01-02"
+
+// holidayLayouts are the date formats accepted in holiday files
+var holidayLayouts = []string{holidayLayout, "20060102", "2006/01/02", "1/2/2006"}
+
+// Calendar defines which days are skipped by business day calculations
+// Holidays maps a date in "2006-01-02" format to the name of the holiday
+type Calendar struct {
+	Weekend  []time.Weekday
+	Holidays map[string]string
+}
+
+// NewCalendar return a Calendar without any holidays
+// when no weekend days are given, Saturday and Sunday are used
+func NewCalendar(weekend ...time.Weekday) *Calendar {
+	if len(weekend) == 0 {
+		weekend = defaultWeekend
+	}
+	return &Calendar{Weekend: weekend, Holidays: make(map[string]string)}
+}
+
+// LoadCalendar return a Calendar with a Saturday and Sunday weekend
+// and holidays read from each of the given files
+func LoadCalendar(paths ...string) (*Calendar, error) {
+	c := NewCalendar()
+	for _, path := range paths {
+		err := c.Load(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// AddHoliday mark the date of t as a holiday
+func (c *Calendar) AddHoliday(t time.Time, name string) {
+	if c.Holidays == nil {
+		c.Holidays = make(map[string]string)
+	}
+	c.Holidays[t.Format(holidayLayout)] = name
+}
+
+// IsHoliday return true when the date of t is one of the holidays
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	_, ok := c.Holidays[t.Format(holidayLayout)]
+	return ok
+}
+
+// IsBusinessDay return true when t is neither on a weekend nor a holiday
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	return !isWeekend(t, c.Weekend) && !c.IsHoliday(t)
+}
+
+// withWeekend return a copy of c using a different weekend, c may be nil
+func (c *Calendar) withWeekend(weekend []time.Weekday) *Calendar {
+	if c == nil {
+		return NewCalendar(weekend...)
+	}
+	return &Calendar{Weekend: weekend, Holidays: c.Holidays}
+}
+
+// Load add the holidays found in an ICS, YAML or CSV file
+// the format is chosen by the file extension: .ics, .yaml, .yml or .csv
+func (c *Calendar) Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics", ".ical":
+		err = c.ReadICS(file)
+	case ".yaml", ".yml":
+		err = c.ReadYAML(file)
+	case ".csv":
+		err = c.ReadCSV(file)
+	default:
+		return fmt.Errorf("[Load] Unsupported holiday file: %s; use .ics, .yaml, .yml or .csv", path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// icsRepeatYears is how far a repeating event is expanded at most, from its DTSTART
+const icsRepeatYears int = 100
+
+// icsEvent holds the properties of a VEVENT which are used for holidays
+type icsEvent struct {
+	name, dtStart, dtEnd, rrule string
+	exDates                     []string
+}
+
+// ReadICS add the all-day VEVENT entries of an iCalendar file as holidays; an all-day
+// entry has an 8 digit DTSTART such as DTSTART;VALUE=DATE:20241225, timed entries are skipped
+// an event spanning several days adds each of those days, as DTEND is exclusive
+// a yearly or weekly RRULE is expanded for up to icsRepeatYears years and EXDATE days are left out;
+// any other RRULE is an error
+func (c *Calendar) ReadICS(r io.Reader) error {
+	var inEvent bool
+	var event icsEvent
+
+	for _, line := range unfoldICS(r) {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		// drop parameters such as DTSTART;VALUE=DATE
+		key, _, _ = strings.Cut(strings.ToUpper(key), ";")
+		switch {
+		case key == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			event = icsEvent{}
+		case key == "END" && value == "VEVENT":
+			inEvent = false
+			if err := c.addICSEvent(event); err != nil {
+				return err
+			}
+		case inEvent && key == "SUMMARY":
+			event.name = value
+		case inEvent && key == "DTSTART":
+			event.dtStart = value
+		case inEvent && key == "DTEND":
+			event.dtEnd = value
+		case inEvent && key == "RRULE":
+			event.rrule = value
+		case inEvent && key == "EXDATE":
+			event.exDates = append(event.exDates, strings.Split(value, ",")...)
+		}
+	}
+	return nil
+}
+
+// addICSEvent add each day of an all-day event, and of its repetitions, as holidays
+func (c *Calendar) addICSEvent(event icsEvent) error {
+	start, err := parseICSDate(event.dtStart)
+	if err != nil {
+		return err
+	}
+	if len(event.dtStart) != len("20060102") {
+		return nil
+	}
+	days := 1
+	if len(event.dtEnd) > 0 {
+		end, err := parseICSDate(event.dtEnd)
+		if err != nil {
+			return err
+		}
+		days = max(int(end.Sub(start).Hours()/24), 1)
+	}
+	skip := make(map[string]bool)
+	for _, exDate := range event.exDates {
+		t, err := parseICSDate(exDate)
+		if err != nil {
+			return err
+		}
+		skip[t.Format(holidayLayout)] = true
+	}
+
+	allStarts := []time.Time{start}
+	if len(event.rrule) > 0 {
+		allStarts, err = expandRRule(event.rrule, start)
+		if err != nil {
+			return err
+		}
+	}
+	for _, first := range allStarts {
+		if skip[first.Format(holidayLayout)] {
+			continue
+		}
+		for i := 0; i < days; i++ {
+			c.AddHoliday(first.AddDate(0, 0, i), event.name)
+		}
+	}
+	return nil
+}
+
+// expandRRule return the start of each occurrence of a FREQ=YEARLY or FREQ=WEEKLY rule with
+// an optional INTERVAL, COUNT and UNTIL; BYMONTH, BYMONTHDAY and BYDAY are only allowed
+// when they repeat the month, day or weekday of "start", as other dates are not expanded
+func expandRRule(rrule string, start time.Time) ([]time.Time, error) {
+	var years, days, count int
+	interval := 1
+	limit := start.AddDate(icsRepeatYears, 0, 0)
+	until := limit
+	for _, item := range strings.Split(strings.ToUpper(rrule), ";") {
+		name, value, _ := strings.Cut(item, "=")
+		var err error
+		switch {
+		case name == "FREQ" && value == "YEARLY":
+			years = 1
+		case name == "FREQ" && value == "WEEKLY":
+			days = 7
+		case name == "INTERVAL":
+			interval, err = strconv.Atoi(value)
+			if err == nil && interval < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case name == "COUNT":
+			count, err = strconv.Atoi(value)
+			if err == nil && count < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case name == "UNTIL":
+			until, err = parseICSDate(value)
+		case name == "BYMONTH" && value == strconv.Itoa(int(start.Month())):
+		case name == "BYMONTHDAY" && value == strconv.Itoa(start.Day()):
+		case name == "BYDAY" && value == strings.ToUpper(start.Weekday().String()[:2]):
+		case name == "WKST":
+		default:
+			return nil, fmt.Errorf("[ReadICS] Unsupported RRULE: %s; only yearly and weekly repeats of DTSTART are expanded", rrule)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[ReadICS] Invalid RRULE: %s; %s %w", rrule, name, err)
+		}
+	}
+	if years == 0 && days == 0 {
+		return nil, fmt.Errorf("[ReadICS] Unsupported RRULE: %s; only yearly and weekly repeats of DTSTART are expanded", rrule)
+	}
+
+	if until.After(limit) {
+		until = limit
+	}
+
+	var allStarts []time.Time
+	for i := 0; count == 0 || len(allStarts) < count; i++ {
+		// a February 29 event only repeats in leap years, AddDate would move it to March 1
+		next := start.AddDate(i*interval*years, 0, i*interval*days)
+		if next.After(until) {
+			break
+		}
+		if days > 0 || next.Day() == start.Day() {
+			allStarts = append(allStarts, next)
+		}
+	}
+	return allStarts, nil
+}
+
+// unfoldICS return the lines of an iCalendar file, joining continuation
+// lines which start with a space or a tab to the previous line
+func unfoldICS(r io.Reader) []string {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICSDate parse the date portion of an ICS DATE or DATE-TIME value
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("[ReadICS] Invalid date: %s", value)
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("[ReadICS] Invalid date: %s", value)
+	}
+	return t, nil
+}
+
+// yamlHoliday is either a plain date or a mapping with "date" and "name" keys
+type yamlHoliday struct {
+	Date string `yaml:"date"`
+	Name string `yaml:"name"`
+}
+
+func (h *yamlHoliday) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		h.Date = node.Value
+		return nil
+	}
+	type plain yamlHoliday
+	return node.Decode((*plain)(h))
+}
+
+// yamlCalendar is the layout of a YAML holiday file, the weekend is optional:
+//
+//	weekend: [sat, sun]
+//	holidays:
+//	  - date: 2025-01-01
+//	    name: New Year's Day
+//	  - 2025-12-25
+type yamlCalendar struct {
+	Weekend  []string      `yaml:"weekend"`
+	Holidays []yamlHoliday `yaml:"holidays"`
+}
+
+// ReadYAML add the holidays of a YAML file; the file can either be a list of
+// holidays or a mapping with "holidays" and an optional "weekend" list
+func (c *Calendar) ReadYAML(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var cal yamlCalendar
+	if err = yaml.Unmarshal(data, &cal); err != nil {
+		if err = yaml.Unmarshal(data, &cal.Holidays); err != nil {
+			return fmt.Errorf("[ReadYAML] %w", err)
+		}
+	}
+
+	if len(cal.Weekend) > 0 {
+		weekend, err := ParseWeekend(strings.Join(cal.Weekend, ","))
+		if err != nil {
+			return err
+		}
+		c.Weekend = weekend
+	}
+	for _, h := range cal.Holidays {
+		t, err := parseHolidayDate(h.Date)
+		if err != nil {
+			return fmt.Errorf("[ReadYAML] %w", err)
+		}
+		c.AddHoliday(t, h.Name)
+	}
+	return nil
+}
+
+// ReadCSV add the holidays of a CSV file with a date in the first column
+// and an optional name in the second column; a header row is skipped
+func (c *Calendar) ReadCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("[ReadCSV] %w", err)
+		}
+		t, err := parseHolidayDate(record[0])
+		if err != nil {
+			if row == 0 {
+				continue
+			}
+			return fmt.Errorf("[ReadCSV] line %d: %w", row+1, err)
+		}
+		name := ""
+		if len(record) > 1 {
+			name = record[1]
+		}
+		c.AddHoliday(t, name)
+	}
+}
+
+// parseHolidayDate parse a date using one of the holidayLayouts
+func parseHolidayDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range holidayLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid date: %s", value)
+}
@@ -0,0 +1,238 @@
+package dtdiff
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testICS string = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20240619
+DTEND;VALUE=DATE:20240620
+SUMMARY:Juneteenth
+END:VEVENT
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20241225
+DTEND;VALUE=DATE:20241227
+SUMMARY:Christmas
+  Break
+END:VEVENT
+END:VCALENDAR
+`
+
+const testYAML string = `weekend: [fri, sat]
+holidays:
+  - date: 2024-06-19
+    name: Juneteenth
+  - 2024-07-04
+`
+
+const testCSV string = `date,name
+2024-06-19,Juneteenth
+2024-07-04,"Independence Day, USA"
+`
+
+func testCalendarContains(t *testing.T, cal *Calendar, allDates []string) {
+	for _, date := range allDates {
+		d, _ := time.Parse(holidayLayout, date)
+		if !cal.IsHoliday(d) {
+			t.Errorf("[date: %v] is not a holiday: %v", date, cal.Holidays)
+		}
+	}
+}
+
+func TestReadICS(t *testing.T) {
+	cal := NewCalendar()
+	err := cal.ReadICS(strings.NewReader(testICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCalendarContains(t, cal, []string{"2024-06-19", "2024-12-25", "2024-12-26"})
+	if len(cal.Holidays) != 3 {
+		t.Errorf("[computed: %v] != [correct: %v]", len(cal.Holidays), 3)
+	}
+	if cal.Holidays["2024-12-25"] != "Christmas Break" {
+		t.Errorf("[computed: %v] != [correct: %v]", cal.Holidays["2024-12-25"], "Christmas Break")
+	}
+}
+
+const testRRuleICS string = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20221225
+DTEND;VALUE=DATE:20221226
+RRULE:FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=25
+SUMMARY:Christmas
+END:VEVENT
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20240607
+RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=3
+EXDATE;VALUE=DATE:20240621
+SUMMARY:Summer Friday
+END:VEVENT
+BEGIN:VEVENT
+DTSTART:20240610T090000Z
+DTEND:20240610T100000Z
+SUMMARY:Meeting
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestReadICSRRule(t *testing.T) {
+	cal := NewCalendar()
+	err := cal.ReadICS(strings.NewReader(testRRuleICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCalendarContains(t, cal, []string{"2022-12-25", "2024-12-25", "2121-12-25", "2024-06-07", "2024-07-05"})
+	for _, date := range []string{"2123-12-25", "2024-06-14", "2024-06-21", "2024-07-19", "2024-06-10"} {
+		if _, ok := cal.Holidays[date]; ok {
+			t.Errorf("[date: %v] should not be a holiday", date)
+		}
+	}
+
+	// 2024-12-25 is a Wednesday
+	dt := New("2024-12-24", "2024-12-27")
+	dt.SetCalendar(cal)
+	business, err := dt.BusinessDays()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if business != 2 {
+		t.Errorf("[computed: %v] != [correct: %v]", business, 2)
+	}
+
+	for _, rrule := range []string{"FREQ=MONTHLY", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", "FREQ=WEEKLY;BYDAY=MO,TU", "FREQ=YEARLY;COUNT=0"} {
+		ics := "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20241225\nRRULE:" + rrule + "\nEND:VEVENT\n"
+		if err := NewCalendar().ReadICS(strings.NewReader(ics)); err == nil {
+			t.Errorf("[rrule: %v] expected an error", rrule)
+		}
+	}
+}
+
+func TestReadYAML(t *testing.T) {
+	cal := NewCalendar()
+	err := cal.ReadYAML(strings.NewReader(testYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCalendarContains(t, cal, []string{"2024-06-19", "2024-07-04"})
+	if len(cal.Weekend) != 2 || cal.Weekend[0] != time.Friday {
+		t.Errorf("[computed: %v] != [correct: %v]", cal.Weekend, "[Friday Saturday]")
+	}
+
+	cal = NewCalendar()
+	err = cal.ReadYAML(strings.NewReader("- 2024-06-19\n- date: 2024-07-04\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCalendarContains(t, cal, []string{"2024-06-19", "2024-07-04"})
+}
+
+func TestReadCSV(t *testing.T) {
+	cal := NewCalendar()
+	err := cal.ReadCSV(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCalendarContains(t, cal, []string{"2024-06-19", "2024-07-04"})
+	if cal.Holidays["2024-07-04"] != "Independence Day, USA" {
+		t.Errorf("[computed: %v] != [correct: %v]", cal.Holidays["2024-07-04"], "Independence Day, USA")
+	}
+
+	err = cal.ReadCSV(strings.NewReader("2024-06-19\nnot a date\n"))
+	if err == nil {
+		t.Error("expected an error for an invalid date")
+	}
+}
+
+func TestLoadCalendar(t *testing.T) {
+	dir := t.TempDir()
+	icsFile := filepath.Join(dir, "holidays.ics")
+	csvFile := filepath.Join(dir, "holidays.csv")
+	if err := os.WriteFile(icsFile, []byte(testICS), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(csvFile, []byte(testCSV), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cal, err := LoadCalendar(icsFile, csvFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCalendarContains(t, cal, []string{"2024-06-19", "2024-07-04", "2024-12-26"})
+
+	if _, err = LoadCalendar(filepath.Join(dir, "holidays.txt")); err == nil {
+		t.Error("expected an error for an unsupported file")
+	}
+
+	// the error of the file is wrapped with its path
+	yamlFile := filepath.Join(dir, "weekend.yaml")
+	if err := os.WriteFile(yamlFile, []byte("weekend: [mon, tue, wed, thu, fri, sat, sun]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadCalendar(yamlFile); !errors.Is(err, ErrInvalidCalendar) || !strings.Contains(err.Error(), yamlFile) {
+		t.Errorf("[computed: %v] is not: [correct: %v]", err, ErrInvalidCalendar)
+	}
+}
+
+func TestBusinessDaysWithHolidays(t *testing.T) {
+	cal := NewCalendar()
+	if err := cal.ReadICS(strings.NewReader(testICS)); err != nil {
+		t.Fatal(err)
+	}
+
+	future, err := Add("2024-06-14", "10BD", WithCalendar(cal))
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(future, "2024-07-01") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v]", future, "2024-07-01")
+	}
+
+	// Saturday 2024-12-28 is a business day with a Sunday-only weekend
+	past, err := Sub("2024-12-30", "3BD", WithCalendar(cal), WithWeekend(time.Sunday))
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(past, "2024-12-24") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v]", past, "2024-12-24")
+	}
+}
+
+func TestDtDiffBusinessDays(t *testing.T) {
+	cal := NewCalendar()
+	if err := cal.ReadICS(strings.NewReader(testICS)); err != nil {
+		t.Fatal(err)
+	}
+
+	allStarts := []string{"2024-06-14", "2024-06-28", "2024-06-15 10:00:00", "2024-06-14"}
+	allEnds := []string{"2024-06-28", "2024-06-14", "2024-06-17 08:00:00", "2024-06-14"}
+	allCorrect := []int{9, -9, 1, 0}
+	for i := range allStarts {
+		dt := New(allStarts[i], allEnds[i])
+		dt.SetCalendar(cal)
+		count, err := dt.BusinessDays()
+		if err != nil {
+			t.Error(err)
+		}
+		if count != allCorrect[i] {
+			t.Errorf("[start: %v] [end: %v] [computed: %v] != [correct: %v]", allStarts[i], allEnds[i], count, allCorrect[i])
+		}
+	}
+
+	dt := New("2024-06-14", "2024-06-28")
+	count, err := dt.BusinessDays()
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 10 {
+		t.Errorf("[computed: %v] != [correct: %v]", count, 10)
+	}
+}
@@ -0,0 +1,31 @@
+package dtdiff
+
+import (
+	"time"
+)
+
+// Clock returns the current time, which "now" and relative dates such as "tomorrow" are based on
+type Clock interface {
+	Now() time.Time
+}
+
+// fixedClock always returns the same time
+type fixedClock time.Time
+
+// Now return the fixed time
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// FixedClock return a Clock which always returns t, such as for reproducible results or tests
+func FixedClock(t time.Time) Clock {
+	return fixedClock(t)
+}
+
+// WithClock base "now" and relative dates on clock instead of the system time
+// the clock is read once per operation, so every relative date of one call is based on the same instant
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
@@ -0,0 +1,78 @@
+package dtdiff
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// steppingClock advances by one day each time it is read
+type steppingClock struct {
+	t time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	c.t = c.t.AddDate(0, 0, 1)
+	return c.t
+}
+
+func TestFixedClock(t *testing.T) {
+	utc, _ := LoadLocation("UTC")
+	clock := WithClock(FixedClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+	tests := []struct {
+		value   string
+		correct string
+	}{
+		{"now", "2024-05-01 12:00:00 +0000 UTC"},
+		{"tomorrow", "2024-05-02 12:00:00 +0000 UTC"},
+		{"next friday", "2024-05-03 12:00:00 +0000 UTC"},
+		{"10:30", "2024-05-01 10:30:00 +0000 UTC"},
+	}
+	for _, test := range tests {
+		computed, err := Parse(test.value, clock, WithLocation(utc))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if computed.String() != test.correct {
+			t.Errorf("[value: %v] [computed: %v] != [correct: %v]", test.value, computed, test.correct)
+		}
+	}
+
+	future, err := Add("now", "1 day", clock, WithLocation(utc))
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.HasPrefix(future, "2024-05-02 12:00:00") {
+		t.Errorf("[computed: %v] does not start with: [correct: %v]", future, "2024-05-02 12:00:00")
+	}
+}
+
+func TestClockReadOnce(t *testing.T) {
+	utc, _ := LoadLocation("UTC")
+	clock := &steppingClock{t: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+
+	result, err := Eval("tomorrow - now", WithClock(clock), WithLocation(utc))
+	if err != nil {
+		t.Error(err)
+	}
+	if result != "1 day" {
+		t.Errorf("[computed: %v] != [correct: %v]", result, "1 day")
+	}
+
+	all, err := AddUntil("now", "tomorrow", "1D", WithClock(clock), WithLocation(utc))
+	if err != nil {
+		t.Error(err)
+	}
+	if len(all) != 1 {
+		t.Errorf("[computed: %v] != [correct: %v]", len(all), 1)
+	}
+
+	// a DtDiff reads the clock each time the difference is calculated
+	dt, _ := NewWithOptions("2024-05-01T12:00:00Z", "now", WithClock(clock), WithLocation(utc), WithUnits("hours"))
+	first, _, _ := dt.DtDiff()
+	second, _, _ := dt.DtDiff()
+	if first == second {
+		t.Errorf("[computed: %v] == [computed: %v]", first, second)
+	}
+}
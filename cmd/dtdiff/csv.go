@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jftuga/dtdiff"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// csvColumns holds the zero-based indexes of the columns selected with --columns
+// a value of -1 means that the column was not selected
+type csvColumns struct {
+	start  int
+	end    int
+	from   int
+	period int
+	// fixed period given with -A or -S when no period column is selected
+	fixedPeriod string
+	// index 0 = add; index = 1 = sub
+	index int
+}
+
+// splitFields split a single line of delimited input, honoring quoted fields
+// such as "Jan 2, 2024 10:00",2024-01-03
+func splitFields(line string, comma rune) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader.Read()
+}
+
+// csvComma return the delimiter of a CSV or TSV file
+// files ending in .tsv or .tab are tab-delimited; STDIN is tab-delimited when its first line contains a tab
+func csvComma(path string, input *bufio.Reader) rune {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsv", ".tab":
+		return '\t'
+	case ".csv":
+		return ','
+	}
+	line, _ := input.Peek(4096)
+	first, _, _ := strings.Cut(string(line), "\n")
+	if strings.Contains(first, "\t") {
+		return '\t'
+	}
+	return ','
+}
+
+// columnIndex return the zero-based index of a column given by its header name or 1-based number
+func columnIndex(header []string, ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(header) {
+			return -1, fmt.Errorf("column number out of range: %d; the file has %d columns", n, len(header))
+		}
+		return n - 1, nil
+	}
+	for i, name := range header {
+		if strings.TrimSpace(name) == ref {
+			return i, nil
+		}
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), ref) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("column not found: %s", ref)
+}
+
+// selectColumns convert --columns into column indexes
+// "spec" is either two columns used as start and end, such as "created,closed" or "2,5",
+// or a list of key=column pairs using the keys: start, end, from, add, sub
+// when only "from" is given, the period comes from -A or -S
+func selectColumns(header []string, spec string) (csvColumns, error) {
+	cols := csvColumns{start: -1, end: -1, from: -1, period: -1}
+	positional := []*int{&cols.start, &cols.end}
+	for _, entry := range strings.Split(spec, ",") {
+		key, ref, keyed := strings.Cut(entry, "=")
+		var target *int
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "start":
+			target = &cols.start
+		case "end":
+			target = &cols.end
+		case "from":
+			target = &cols.from
+		case "add":
+			target = &cols.period
+		case "sub":
+			target = &cols.period
+			cols.index = 1
+		default:
+			if keyed || len(positional) == 0 {
+				return cols, fmt.Errorf("invalid --columns: %s; use start,end or key=column pairs with start, end, from, add or sub", spec)
+			}
+			target, ref = positional[0], entry
+			positional = positional[1:]
+		}
+		i, err := columnIndex(header, ref)
+		if err != nil {
+			return cols, err
+		}
+		*target = i
+	}
+
+	if cols.from >= 0 {
+		if cols.start >= 0 || cols.end >= 0 {
+			return cols, fmt.Errorf("invalid --columns: %s; from can not be used with start or end", spec)
+		}
+		if cols.period >= 0 && (len(add) > 0 || len(sub) > 0) {
+			return cols, fmt.Errorf("invalid --columns: %s; a period column can not be used with -A or -S", spec)
+		}
+		if cols.period < 0 {
+			if len(add) == 0 && len(sub) == 0 {
+				return cols, fmt.Errorf("invalid --columns: %s; use an add or sub column, or -A or -S", spec)
+			}
+			cols.fixedPeriod, cols.index = add, 0
+			if len(sub) > 0 {
+				cols.fixedPeriod, cols.index = sub, 1
+			}
+		}
+		return cols, nil
+	}
+	if cols.start < 0 || cols.end < 0 {
+		return cols, fmt.Errorf("invalid --columns: %s; both start and end columns are needed", spec)
+	}
+	return cols, nil
+}
+
+// names return the headers of the columns appended to each row
+func (cols csvColumns) names() []string {
+	if cols.from >= 0 {
+		return []string{"result"}
+	}
+	totalUnit := "seconds"
+	if len(unit) > 0 {
+		totalUnit = unit
+	}
+	return []string{"duration", "brief", "total_" + totalUnit}
+}
+
+// field return column i of record or an error when the row is too short
+func field(record []string, i int) (string, error) {
+	if i >= len(record) {
+		return "", newCodedError(codeInvalidInput, fmt.Errorf("row has %d columns, column %d is missing", len(record), i+1))
+	}
+	return record[i], nil
+}
+
+// compute return the values appended to one row
+func (cols csvColumns) compute(record []string) ([]string, error) {
+	if cols.from >= 0 {
+		return cols.computeFrom(record)
+	}
+	start, err := field(record, cols.start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := field(record, cols.end)
+	if err != nil {
+		return nil, err
+	}
+
+	dt := newDtDiff(start, end)
+	human, _, err := dt.DtDiff()
+	if err != nil {
+		return nil, newCodedError(codeInvalidDate, err)
+	}
+	dt.SetBrief(true)
+	brief, _, _ := dt.DtDiff()
+	totalUnit := "seconds"
+	if len(unit) > 0 {
+		totalUnit = unit
+	}
+	total, err := dt.Total(totalUnit, decimals)
+	if err != nil {
+		return nil, newCodedError(codeInvalidUnit, err)
+	}
+	return []string{human, brief, total}, nil
+}
+
+// computeFrom return the result of adding or subtracting the period of one row
+func (cols csvColumns) computeFrom(record []string) ([]string, error) {
+	from, err := field(record, cols.from)
+	if err != nil {
+		return nil, err
+	}
+	period := cols.fixedPeriod
+	if cols.period >= 0 {
+		if period, err = field(record, cols.period); err != nil {
+			return nil, err
+		}
+	}
+	calc := dtdiff.Add
+	if cols.index == 1 {
+		calc = dtdiff.Sub
+	}
+	result, err := calc(from, period, textOptions()...)
+	if err != nil {
+		return nil, newCodedError(codeInvalidPeriod, err)
+	}
+	return []string{result}, nil
+}
+
+// alignRow return "record" with the computed columns of "cols" appended after its first "width" columns,
+// so that they line up with the header; short rows are padded, while a row with more columns than the header
+// is truncated and reported as an error; the computed columns are empty when the row fails
+func (cols csvColumns) alignRow(record []string, width int) ([]string, error) {
+	var values []string
+	var err error
+	if len(record) > width {
+		err = newCodedError(codeInvalidInput, fmt.Errorf("row has %d columns, the header has %d", len(record), width))
+		record = record[:width]
+	} else {
+		values, err = cols.compute(record)
+	}
+	if err != nil {
+		values = make([]string, len(cols.names()))
+	}
+	for len(record) < width {
+		record = append(record, "")
+	}
+	return append(record, values...), err
+}
+
+// checkHeader return an error when two columns of "header" have the same name,
+// which would be merged into a single key of structured output
+func checkHeader(header []string) error {
+	seen := make(map[string]bool, len(header))
+	for _, name := range header {
+		if seen[name] {
+			return fmt.Errorf("duplicate column name: %s; column names must be unique with -o json or -o yaml", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// csvRow is one row of structured output, an object whose keys are in the order of the header
+type csvRow struct {
+	header []string
+	record []string
+}
+
+// MarshalJSON write the columns of the row in the order of the header
+func (r csvRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range r.header {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		value, _ := json.Marshal(r.record[i])
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML write the columns of the row in the order of the header
+func (r csvRow) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i, name := range r.header {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: r.record[i]})
+	}
+	return node, nil
+}
+
+// runCSV read a CSV or TSV file with a header row and write every row back with the
+// computed columns appended; "-" reads from STDIN; rows that fail keep empty computed
+// columns and are reported to STDERR, in which case the exit code is 1
+// with -o json or -o yaml, each row is written as an object keyed by the header, in column order
+func runCSV(path, spec string) {
+	var file io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fatal(codeInvalidInput, err)
+		}
+		defer f.Close()
+		file = f
+	}
+	input := bufio.NewReader(file)
+	reader := csv.NewReader(input)
+	reader.Comma = csvComma(path, input)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		fatal(codeInvalidInput, fmt.Errorf("unable to read the header of %s: %w", path, err))
+	}
+	cols, err := selectColumns(header, spec)
+	if err != nil {
+		fatal(codeInvalidInput, err)
+	}
+	width := len(header)
+	header = append(header, cols.names()...)
+	if structured() {
+		if err := checkHeader(header); err != nil {
+			fatal(codeInvalidInput, err)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	writer := csv.NewWriter(out)
+	writer.Comma = reader.Comma
+	if !structured() {
+		writer.Write(header)
+	}
+	failed := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			line := 0
+			if errors.As(err, &parseErr) {
+				line = parseErr.Line
+			}
+			failed++
+			reportLine(line, newCodedError(codeInvalidInput, err))
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		record, err = cols.alignRow(record, width)
+		if err != nil {
+			failed++
+			reportLine(line, err)
+		}
+
+		if !structured() {
+			writer.Write(record)
+			continue
+		}
+		writer.Flush()
+		emitRecord(out, nil, csvRow{header: header, record: record})
+	}
+	writer.Flush()
+	out.Flush()
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSplitFields(t *testing.T) {
+	tests := []struct {
+		line    string
+		comma   rune
+		correct []string
+	}{
+		{"2024-01-01,2024-01-02", ',', []string{"2024-01-01", "2024-01-02"}},
+		{`"Jan 2, 2024 10:00",2024-01-03`, ',', []string{"Jan 2, 2024 10:00", "2024-01-03"}},
+		{`"say ""hi""", 2024-01-03`, ',', []string{`say "hi"`, "2024-01-03"}},
+		{"2024-01-01\t2024-01-02,x", '\t', []string{"2024-01-01", "2024-01-02,x"}},
+	}
+	for _, test := range tests {
+		fields, err := splitFields(test.line, test.comma)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if strings.Join(fields, "|") != strings.Join(test.correct, "|") {
+			t.Errorf("[line: %v] [computed: %q] != [correct: %q]", test.line, fields, test.correct)
+		}
+	}
+	if _, err := splitFields(`"unterminated,2024-01-01`, ','); err == nil {
+		t.Errorf("splitFields should fail for an unterminated quote")
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	header := []string{"id", " Created ", "closed", "created"}
+	tests := []struct {
+		ref     string
+		correct int
+	}{
+		{"1", 0},
+		{"4", 3},
+		{"closed", 2},
+		{"Created", 1},
+		// an exact match is preferred over a match in another case
+		{"created", 3},
+		{"CLOSED", 2},
+		{"0", -1},
+		{"5", -1},
+		{"missing", -1},
+	}
+	for _, test := range tests {
+		i, err := columnIndex(header, test.ref)
+		if i != test.correct || (err != nil) != (test.correct == -1) {
+			t.Errorf("[ref: %v] [computed: %v %v] != [correct: %v]", test.ref, i, err, test.correct)
+		}
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	header := []string{"id", "created", "closed", "period"}
+	tests := []struct {
+		spec    string
+		correct csvColumns
+		fails   bool
+	}{
+		{"created,closed", csvColumns{start: 1, end: 2, from: -1, period: -1}, false},
+		{"2,3", csvColumns{start: 1, end: 2, from: -1, period: -1}, false},
+		{"end=closed,start=created", csvColumns{start: 1, end: 2, from: -1, period: -1}, false},
+		{"from=created,sub=period", csvColumns{start: -1, end: -1, from: 1, period: 3, index: 1}, false},
+		{"created", csvColumns{}, true},
+		{"created,closed,id", csvColumns{}, true},
+		{"from=created,start=closed", csvColumns{}, true},
+		{"from=created", csvColumns{}, true},
+		{"bogus=created", csvColumns{}, true},
+	}
+	for _, test := range tests {
+		cols, err := selectColumns(header, test.spec)
+		if test.fails {
+			if err == nil {
+				t.Errorf("[spec: %v] should fail", test.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if cols != test.correct {
+			t.Errorf("[spec: %v] [computed: %+v] != [correct: %+v]", test.spec, cols, test.correct)
+		}
+	}
+}
+
+func TestAlignRow(t *testing.T) {
+	cols := csvColumns{start: 0, end: 1, from: -1, period: -1}
+	tests := []struct {
+		record  []string
+		correct []string
+		fails   bool
+	}{
+		{[]string{"2024-01-01", "2024-01-02", "x"}, []string{"2024-01-01", "2024-01-02", "x", "1 day", "1D", "86400"}, false},
+		// short rows are padded
+		{[]string{"2024-01-01", "2024-01-02"}, []string{"2024-01-01", "2024-01-02", "", "1 day", "1D", "86400"}, false},
+		// long rows are truncated and fail
+		{[]string{"2024-01-01", "2024-01-02", "x", "extra"}, []string{"2024-01-01", "2024-01-02", "x", "", "", ""}, true},
+		{[]string{"2024-01-01"}, []string{"2024-01-01", "", "", "", "", ""}, true},
+	}
+	for _, test := range tests {
+		record, err := cols.alignRow(test.record, 3)
+		if (err != nil) != test.fails {
+			t.Errorf("[record: %q] [computed: %v] != [correct: %v]", test.record, err, test.fails)
+		}
+		if strings.Join(record, "|") != strings.Join(test.correct, "|") {
+			t.Errorf("[record: %q] [computed: %q] != [correct: %q]", test.record, record, test.correct)
+		}
+	}
+}
+
+func TestCSVRow(t *testing.T) {
+	if err := checkHeader([]string{"start", "end", "Start"}); err != nil {
+		t.Error(err)
+	}
+	if err := checkHeader([]string{"start", "duration", "duration"}); err == nil {
+		t.Errorf("checkHeader should fail for a duplicate column name")
+	}
+
+	row := csvRow{header: []string{"z", "a", "m"}, record: []string{"1", `"2"`, ""}}
+	data, err := json.Marshal(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	correct := `{"z":"1","a":"\"2\"","m":""}`
+	if string(data) != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", string(data), correct)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/jftuga/dtdiff"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+const evalUsageTemplate string = `Usage:
+ {{.UseLine}}
+
+Examples:
+{{.Example}}
+
+Operations:
+datetime + period, datetime - period, period + datetime
+datetime - datetime (returns a period: the calendar is walked for two dates, the same as -s/-e,
+otherwise it is in weeks, days and clock units, so that adding it back gives the same datetime)
+period + period, period - period, period * integer, integer * period (× is also accepted)
+operators must be surrounded by spaces; use parentheses to group operations
+
+Flags:
+{{FlagUsagesCustom .InheritedFlags "output" "tz" "in-tz" "weekend" "holidays" "sum-units" "layout" "strftime" "nonewline" | trimTrailingWhitespaces}}
+`
+
+// evalResult is the structured output of the eval command
+type evalResult struct {
+	Expression string `json:"expression" yaml:"expression"`
+	Result     string `json:"result" yaml:"result"`
+	Formatted  string `json:"formatted,omitempty" yaml:"formatted,omitempty"`
+}
+
+var evalCmd = &cobra.Command{
+	Use:   "eval expression",
+	Short: "evaluate a date arithmetic expression",
+	Example: ` dtdiff eval '2024-01-01 + 3D - 2h'
+ dtdiff eval '(2024-06-01 - 2024-01-01) * 2'
+ dtdiff eval 'today + 2 weeks - 1 business day'`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		validateOutput()
+		setLocations(tz, inTz)
+		setClock(nowValue)
+		setLocale(lang, langFiles)
+		setCalendar(weekend, holidays)
+		setLayout(layout, strftime)
+		setPeriodOptions(sumUnits)
+		expression := strings.Join(args, " ")
+		format, err := dtdiff.Eval(expression, textOptions()...)
+		if err != nil {
+			fatal(codeInvalidExpression, err)
+		}
+
+		var result evalResult
+		if structured() {
+			plain, _ := dtdiff.Eval(expression, calcOptions...)
+			result = evalResult{Expression: expression, Result: resultToRFC3339(plain), Formatted: formatted(format)}
+		}
+		emit(format, result)
+	},
+}
+
+func init() {
+	evalCmd.SetUsageTemplate(evalUsageTemplate)
+	rootCmd.AddCommand(evalCmd)
+}
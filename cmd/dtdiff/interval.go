@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/jftuga/dtdiff"
+)
+
+// intervalResult is the structured output of -E
+type intervalResult struct {
+	Interval string `json:"interval" yaml:"interval"`
+}
+
+// setInterval convert the --interval flag into -s and -e, or into -F with -A or -S and -R
+// for a repeating interval; a start is added to and an end is subtracted from
+func setInterval(interval string) {
+	if len(interval) == 0 {
+		return
+	}
+	iv, err := dtdiff.ParseInterval(interval, calcOptions...)
+	if err != nil {
+		fatal(codeInvalidInterval, err)
+	}
+	if iv.Repetitions == 0 {
+		start, end, err = iv.Bounds(calcOptions...)
+		if err != nil {
+			fatal(codeInvalidInterval, err)
+		}
+		return
+	}
+	recurrence = iv.Repetitions
+	if len(iv.Start) > 0 {
+		from, add = iv.Start, iv.Period
+		return
+	}
+	from, sub = iv.End, iv.Period
+}
+
+// computeStartEndInterval used when -E is given along with -s and -e
+func computeStartEndInterval(start, end string) ([]string, interface{}, error) {
+	dt := newDtDiff(start, end)
+	if _, _, err := dt.DtDiff(); err != nil {
+		return nil, nil, newCodedError(codeInvalidDate, err)
+	}
+	format := dt.Interval().String()
+	return []string{format}, intervalResult{Interval: format}, nil
+}
+
+// computeFromInterval used when -E is given along with -F and -A or -S
+// the result is from/period for -A and period/from for -S, preceded by R<n>/ when -R is given
+func computeFromInterval(from, period string, index int) ([]string, interface{}, error) {
+	iso, err := dtdiff.PeriodToISO8601(period, calcOptions...)
+	if err != nil {
+		return nil, nil, newCodedError(codeInvalidPeriod, err)
+	}
+	parsed, err := parsedFrom(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	iv := dtdiff.Interval{Start: parsed, Period: iso, Repetitions: recurrence}
+	if index == 1 {
+		iv = dtdiff.Interval{End: parsed, Period: iso, Repetitions: recurrence}
+	}
+	format := iv.String()
+	return []string{format}, intervalResult{Interval: format}, nil
+}
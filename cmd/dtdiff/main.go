@@ -7,8 +7,11 @@ import (
 	"github.com/jftuga/dtdiff"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"io"
 	"os"
 	"strings"
+	"time"
+	_ "time/tzdata"
 )
 
 // this constant was generated by ChatGPT and then manually refined
@@ -19,27 +22,44 @@ Aliases:
  {{.NameAndAliases}}{{end}}{{if .HasExample}}
 Examples:
  {{.Example}}{{end}}{{if .HasAvailableSubCommands}}
+
 Available Commands:{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
 
 Globals:
-{{FlagUsagesCustom .LocalFlags "nonewline" "help" "version" | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableLocalFlags}}
+{{FlagUsagesCustom .LocalFlags "nonewline" "help" "version" "output" "tz" "in-tz" "now" "lang" "lang-file" "weekend" "holidays" | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableLocalFlags}}
 
 Flag Group 1 (mutually exclusive with Flag Group 2):
-{{FlagUsagesCustom .LocalFlags "start" "end" "stdin" "brief" | trimTrailingWhitespaces}}
+{{FlagUsagesCustom .LocalFlags "start" "end" "stdin" "batch" "brief" "iso8601" "precision" "round" "truncate" "units" "max-unit" "min-unit" "business-days" "calendar-diff" "unit" "decimals" | trimTrailingWhitespaces}}
 
 Flag Group 2:
-{{FlagUsagesCustom .LocalFlags "from" "add" "sub" "recurrence" "until" | trimTrailingWhitespaces}}
+{{FlagUsagesCustom .LocalFlags "from" "add" "sub" "cron" "recurrence" "until" "sum-units" "layout" "strftime" | trimTrailingWhitespaces}}
+
+ISO 8601 Intervals:
+{{FlagUsagesCustom .LocalFlags "interval" "emit-interval" | trimTrailingWhitespaces}}
+
+CSV Files: (-c, -d, -p, -t, -u, -D, -M, -X, -A, -S, -L and -T also apply)
+{{FlagUsagesCustom .LocalFlags "csv" "columns" | trimTrailingWhitespaces}}
 
 Durations:
-years months weeks days
+years months weeks days business days
 hours minutes seconds milliseconds microseconds nanoseconds
 example: "1 year 2 months 3 days 4 hours 1 minute 6 seconds"
 
 Brief Durations: (dates are upper, times are lower)
-Y    M    W    D
+Y    M    W    D    BD
 h    m    s    ms    us    ns
-examples: 1Y2M3W4D5h6m7s8ms9us1ns, "1Y 2M 3W 4D 5h 6m 7s 8ms 9us 1ns"
+examples: 1Y2M3W4D5h6m7s8ms9us1ns, "1Y 2M 3W 4D 5h 6m 7s 8ms 9us 1ns", 10BD
+each unit can only be given once, unless -m is used to sum repeated units
+amounts can have decimals, such as: 1.5h, "0.5 days", 2.25D
+
+ISO 8601 Durations:
+P[nY][nM][nW][nD][T[nH][nM][nS]], M is months before T and minutes after it
+examples: P1Y2M3DT4H5M6S, P2W, PT1.5S, -P1D
+
+Signed Durations:
+each amount can have its own + or - sign, which reverses the direction of -A or -S for that amount
+examples: "1 month -2 days", 1M-2D, -3h
 
 Relative Dates:
 for the -s, -e, -F, and -U flags, you can use these shortcuts:
@@ -47,6 +67,12 @@ now
 today (returns same value as now)
 yesterday
 tomorrow
+next|last|this <weekday>, such as: next monday, last fri
+next|last week|month|quarter|year
+<duration> ago, such as: 3 days ago, 2h30m ago
+in <duration>, such as: in 2 weeks, in 5 business days
+start of|end of [next|last] day|week|month|quarter|year, such as: end of quarter
+any of these can be followed by a time, such as: tomorrow 9am, last friday 17:00
 example: dtdiff -F today -A 7h10m -U tomorrow
 {{end}}{{if .HasAvailableInheritedFlags}}
 Global Flags:
@@ -66,41 +92,85 @@ var (
 	until         string
 	noNewline     bool
 	readFromStdin bool
+	batch         bool
+	csvFile       string
+	columns       string
+	sumUnits      bool
+	cron          string
 	brief         bool
+	iso8601       bool
+	precision     int
+	round         bool
+	truncate      bool
+	units         string
+	maxUnit       string
+	minUnit       string
+	interval      string
+	emitInterval  bool
+	tz            string
+	inTz          string
+	nowValue      string
+	lang          string
+	langFiles     string
+	weekend       string
+	holidays      string
+	businessDays  bool
+	calendarDiff  bool
+	unit          string
+	decimals      int
+	output        string
+	layout        string
+	strftime      string
 	usageMsg      string
 
+	// set by setLocations and setCalendar
+	calcOptions []dtdiff.Option
+	// set by setLayout; only used for text output and the "formatted" field of structured output
+	layoutOptions []dtdiff.Option
+	// true when --calendar-diff was explicitly given
+	calendarDiffSet bool
+
 	rootCmd = &cobra.Command{
 		Use:     "dtdiff",
 		Version: dtdiff.PgmVersion,
 		Short:   "dtdiff: output the difference between date, time or duration",
 		Run: func(cmd *cobra.Command, args []string) {
-			if (len(start) > 0 && len(end) > 0) || readFromStdin {
-				computeStartEnd(start, end, brief)
+			validateOutput()
+			setLocations(tz, inTz)
+			setClock(nowValue)
+			setLocale(lang, langFiles)
+			setCalendar(weekend, holidays)
+			setLayout(layout, strftime)
+			setPeriodOptions(sumUnits, add, sub)
+			setPrecision(precision, round, truncate)
+			setUnits(units, maxUnit, minUnit)
+			setInterval(interval)
+			calendarDiffSet = cmd.Flags().Changed("calendar-diff")
+			if len(csvFile) > 0 {
+				runCSV(csvFile, columns)
 				return
 			}
+			if batch {
+				readFromStdin = true
+			}
 
-			if len(from) > 0 && len(add) > 0 {
-				if recurrence > 0 {
-					computeAddSubWithRecurrence(from, add, 0, recurrence)
-				} else if len(until) > 0 {
-					computeUntil(from, until, add, 0)
-				} else {
-					computeAddSub(from, add, 0)
-				}
-				return
+			calc, values := selectCalculation()
+			if calc == nil {
+				fmt.Fprintln(os.Stderr, usageMsg)
+				os.Exit(0)
 			}
-			if len(from) > 0 && len(sub) > 0 {
-				if recurrence > 0 {
-					computeAddSubWithRecurrence(from, sub, 1, recurrence)
-				} else if len(until) > 0 {
-					computeUntil(from, until, sub, 1)
-				} else {
-					computeAddSub(from, sub, 1)
-				}
+			if batch {
+				runBatch(calc, len(values))
 				return
 			}
-			fmt.Fprintln(os.Stderr, usageMsg)
-			os.Exit(0)
+			if readFromStdin {
+				values = getInput(len(values))
+			}
+			lines, result, err := calc(values)
+			if err != nil {
+				fatal(errorCodeOf(err), err)
+			}
+			emitLines(lines, result)
 		},
 	}
 )
@@ -135,33 +205,78 @@ func FlagUsagesCustom(flags *pflag.FlagSet, names ...string) string {
 }
 
 func main() {
+	// errors are reported by fatal, so that they are JSON or YAML with --output
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		fatal(codeInvalidInput, err)
 	}
 }
 
 func init() {
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		fatal(codeInvalidInput, err)
+		return err
+	})
 	rootCmd.PersistentFlags().StringVarP(&start, "start", "s", "", "start date, time, or a datetime")
 	rootCmd.PersistentFlags().StringVarP(&end, "end", "e", "", "end date, time, or a datetime")
 	rootCmd.PersistentFlags().StringVarP(&from, "from", "F", "", "a base date, time or datetime to use with -A or -S")
 	rootCmd.PersistentFlags().StringVarP(&add, "add", "A", "", "add: a duration to use with -F, such as '1 day 2 hours 3 seconds'")
 	rootCmd.PersistentFlags().StringVarP(&sub, "sub", "S", "", "subtract: a duration to use with -F, such as '5 months 4 weeks 3 days'")
+	rootCmd.PersistentFlags().StringVarP(&layout, "layout", "L", "", "output layout for -A/-S: a Go layout, rfc3339, rfc1123, iso-date, unix or unixms")
+	rootCmd.PersistentFlags().StringVarP(&strftime, "strftime", "T", "", "output format for -A/-S using strftime directives, such as '%Y-%m-%d %H:%M'")
+	rootCmd.PersistentFlags().StringVarP(&cron, "cron", "j", "", "list the times of a cron expression after -F, such as '*/15 9-17 * * MON-FRI' (next one unless -R or -U)")
+	rootCmd.PersistentFlags().BoolVarP(&sumUnits, "sum-units", "m", false, "allow a unit more than once in -A/-S, such as 1m30s5m, and sum the amounts")
 	rootCmd.PersistentFlags().IntVarP(&recurrence, "recurrence", "R", 0, "repeat period this number of times (mutually exclusive with -U)")
 	rootCmd.PersistentFlags().StringVarP(&until, "until", "U", "", "repeat period until date/time is exceeded")
 	rootCmd.PersistentFlags().BoolVarP(&noNewline, "nonewline", "n", false, "do not output a newline character")
-	rootCmd.PersistentFlags().BoolVarP(&readFromStdin, "stdin", "i", false, "read from STDIN instead of using -s/-e")
+	rootCmd.PersistentFlags().BoolVarP(&readFromStdin, "stdin", "i", false, "read from STDIN instead of using -s/-e, or -F when used with -A/-S")
+	rootCmd.PersistentFlags().BoolVarP(&batch, "batch", "l", false, "process every line of STDIN, one result per line (implies -i)")
+	rootCmd.PersistentFlags().StringVarP(&csvFile, "csv", "C", "", "CSV or TSV file with a header row to process, use - for STDIN (requires -k)")
+	rootCmd.PersistentFlags().StringVarP(&columns, "columns", "k", "", "columns for -C by name or number: start,end or from=col with add=col, sub=col, -A or -S")
 	rootCmd.PersistentFlags().BoolVarP(&brief, "brief", "b", false, "output in brief format, such as: 1Y2M3D4h5m6s7ms8us9ns")
+	rootCmd.PersistentFlags().BoolVarP(&iso8601, "iso8601", "I", false, "output as an ISO 8601 duration, such as: P1Y2M3DT4H5M6.5S")
+	rootCmd.PersistentFlags().IntVarP(&precision, "precision", "p", 0, "only output this number of the most significant units, such as 2 for: 1 year 2 weeks")
+	rootCmd.PersistentFlags().BoolVarP(&round, "round", "r", false, "round the units beyond -p, carrying into the larger units (default)")
+	rootCmd.PersistentFlags().BoolVarP(&truncate, "truncate", "t", false, "drop the units beyond -p instead of rounding them")
+	rootCmd.PersistentFlags().StringVarP(&units, "units", "D", "", "comma-separated units for -s/-e, such as days,hours,minutes for: 17 days 2 hours")
+	rootCmd.PersistentFlags().StringVarP(&maxUnit, "max-unit", "M", "", "largest unit for -s/-e, such as days for: 17 days 2 hours 30 minutes")
+	rootCmd.PersistentFlags().StringVarP(&minUnit, "min-unit", "X", "", "smallest unit for -s/-e, such as hours; sub-second units need -X or -D")
+	rootCmd.PersistentFlags().StringVarP(&interval, "interval", "P", "", "an ISO 8601 interval used instead of -s/-e, or -F/-A/-R for R<n>/..., such as 2024-01-01/P1M")
+	rootCmd.PersistentFlags().BoolVarP(&emitInterval, "emit-interval", "E", false, "output -s/-e or -F/-A/-S/-R as an ISO 8601 interval, such as R5/2024-01-01T00:00:00Z/P1D")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "text", "output format: text, json or yaml")
+	rootCmd.PersistentFlags().StringVarP(&tz, "tz", "z", "", "time zone for dates without an offset, such as Europe/Berlin or +05:30")
+	rootCmd.PersistentFlags().StringVarP(&inTz, "in-tz", "Z", "", "output results in this time zone (defaults to --tz)")
+	rootCmd.PersistentFlags().StringVarP(&nowValue, "now", "N", "", "use this datetime as the current time for now and relative dates, such as 2024-05-01T12:00:00Z")
+	rootCmd.PersistentFlags().StringVarP(&lang, "lang", "g", "", "language of durations: en, de, es, fr, ja or one loaded with -G; brief output is not translated")
+	rootCmd.PersistentFlags().StringVarP(&langFiles, "lang-file", "G", "", "comma-separated .json or .yaml files, each defining a language for -g")
+	rootCmd.PersistentFlags().StringVarP(&weekend, "weekend", "w", "", "comma-separated weekend days for business days (default: sat,sun)")
+	rootCmd.PersistentFlags().StringVarP(&holidays, "holidays", "H", "", "comma-separated .ics, .yaml or .csv holiday files for business days")
+	rootCmd.PersistentFlags().BoolVarP(&businessDays, "business-days", "B", false, "output the number of business days instead of the duration")
+	rootCmd.PersistentFlags().StringVarP(&unit, "unit", "u", "", "output the total difference in a single unit, such as hours or days")
+	rootCmd.PersistentFlags().IntVarP(&decimals, "decimals", "d", 0, "number of digits after the decimal point when using -u")
+	rootCmd.PersistentFlags().BoolVarP(&calendarDiff, "calendar-diff", "c", false, "output true years, months and days (default when -s and -e are both dates)")
 
 	rootCmd.MarkFlagsRequiredTogether("start", "end")
 	rootCmd.MarkFlagsMutuallyExclusive("add", "sub")
 	rootCmd.MarkFlagsMutuallyExclusive("stdin", "start")
 	rootCmd.MarkFlagsMutuallyExclusive("stdin", "end")
 	rootCmd.MarkFlagsMutuallyExclusive("stdin", "from")
-	rootCmd.MarkFlagsMutuallyExclusive("stdin", "add")
-	rootCmd.MarkFlagsMutuallyExclusive("stdin", "sub")
-	rootCmd.MarkFlagsMutuallyExclusive("stdin", "recurrence")
-	rootCmd.MarkFlagsMutuallyExclusive("stdin", "until")
+	rootCmd.MarkFlagsMutuallyExclusive("batch", "start")
+	rootCmd.MarkFlagsMutuallyExclusive("batch", "end")
+	rootCmd.MarkFlagsMutuallyExclusive("batch", "from")
+	rootCmd.MarkFlagsMutuallyExclusive("batch", "nonewline")
+	rootCmd.MarkFlagsRequiredTogether("csv", "columns")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "start")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "end")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "from")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "stdin")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "batch")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "recurrence")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "until")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "business-days")
+	rootCmd.MarkFlagsMutuallyExclusive("csv", "nonewline")
 	rootCmd.MarkFlagsMutuallyExclusive("from", "start")
 	rootCmd.MarkFlagsMutuallyExclusive("from", "end")
 	rootCmd.MarkFlagsMutuallyExclusive("recurrence", "start")
@@ -172,13 +287,77 @@ func init() {
 	rootCmd.MarkFlagsMutuallyExclusive("add", "end")
 	rootCmd.MarkFlagsMutuallyExclusive("sub", "start")
 	rootCmd.MarkFlagsMutuallyExclusive("sub", "end")
+	rootCmd.MarkFlagsMutuallyExclusive("sum-units", "start")
+	rootCmd.MarkFlagsMutuallyExclusive("sum-units", "end")
 	rootCmd.MarkFlagsMutuallyExclusive("brief", "from")
 	rootCmd.MarkFlagsMutuallyExclusive("brief", "add")
 	rootCmd.MarkFlagsMutuallyExclusive("brief", "sub")
 	rootCmd.MarkFlagsMutuallyExclusive("brief", "recurrence")
 	rootCmd.MarkFlagsMutuallyExclusive("brief", "until")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "brief")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "from")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "add")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "sub")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "recurrence")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "until")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "unit")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "business-days")
+	rootCmd.MarkFlagsMutuallyExclusive("iso8601", "csv")
+	rootCmd.MarkFlagsMutuallyExclusive("round", "truncate")
+	for _, name := range []string{"precision", "round", "truncate", "units", "max-unit", "min-unit"} {
+		rootCmd.MarkFlagsMutuallyExclusive(name, "from")
+		rootCmd.MarkFlagsMutuallyExclusive(name, "unit")
+		rootCmd.MarkFlagsMutuallyExclusive(name, "business-days")
+	}
+	for _, name := range []string{"units", "max-unit", "min-unit"} {
+		rootCmd.MarkFlagsMutuallyExclusive(name, "calendar-diff")
+	}
+	for _, name := range []string{"add", "sub", "start", "end", "interval", "emit-interval", "csv", "sum-units", "calendar-diff"} {
+		rootCmd.MarkFlagsMutuallyExclusive("cron", name)
+	}
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "start")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "end")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "from")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "add")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "sub")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "recurrence")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "until")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "stdin")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "batch")
+	rootCmd.MarkFlagsMutuallyExclusive("interval", "csv")
+	rootCmd.MarkFlagsMutuallyExclusive("emit-interval", "until")
+	rootCmd.MarkFlagsMutuallyExclusive("emit-interval", "unit")
+	rootCmd.MarkFlagsMutuallyExclusive("emit-interval", "business-days")
+	rootCmd.MarkFlagsMutuallyExclusive("emit-interval", "brief")
+	rootCmd.MarkFlagsMutuallyExclusive("emit-interval", "iso8601")
+	rootCmd.MarkFlagsMutuallyExclusive("emit-interval", "csv")
+	rootCmd.MarkFlagsMutuallyExclusive("emit-interval", "layout")
+	rootCmd.MarkFlagsMutuallyExclusive("emit-interval", "strftime")
 	rootCmd.MarkFlagsMutuallyExclusive("until", "recurrence")
+	rootCmd.MarkFlagsMutuallyExclusive("calendar-diff", "from")
+	rootCmd.MarkFlagsMutuallyExclusive("calendar-diff", "add")
+	rootCmd.MarkFlagsMutuallyExclusive("calendar-diff", "sub")
+	rootCmd.MarkFlagsMutuallyExclusive("calendar-diff", "recurrence")
+	rootCmd.MarkFlagsMutuallyExclusive("calendar-diff", "until")
+	rootCmd.MarkFlagsMutuallyExclusive("layout", "strftime")
+	rootCmd.MarkFlagsMutuallyExclusive("layout", "start")
+	rootCmd.MarkFlagsMutuallyExclusive("layout", "end")
+	rootCmd.MarkFlagsMutuallyExclusive("strftime", "start")
+	rootCmd.MarkFlagsMutuallyExclusive("strftime", "end")
+	rootCmd.MarkFlagsMutuallyExclusive("unit", "brief")
+	rootCmd.MarkFlagsMutuallyExclusive("unit", "business-days")
+	rootCmd.MarkFlagsMutuallyExclusive("unit", "from")
+	rootCmd.MarkFlagsMutuallyExclusive("unit", "add")
+	rootCmd.MarkFlagsMutuallyExclusive("unit", "sub")
+	rootCmd.MarkFlagsMutuallyExclusive("unit", "recurrence")
+	rootCmd.MarkFlagsMutuallyExclusive("unit", "until")
+	rootCmd.MarkFlagsMutuallyExclusive("business-days", "from")
+	rootCmd.MarkFlagsMutuallyExclusive("business-days", "add")
+	rootCmd.MarkFlagsMutuallyExclusive("business-days", "sub")
+	rootCmd.MarkFlagsMutuallyExclusive("business-days", "recurrence")
+	rootCmd.MarkFlagsMutuallyExclusive("business-days", "until")
 
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	versionTemplate := fmt.Sprintf("%s v%s\n%s\n", dtdiff.PgmName, dtdiff.PgmVersion, dtdiff.PgmUrl)
 	rootCmd.SetVersionTemplate(versionTemplate)
 	// Register the custom template function
@@ -191,124 +370,511 @@ func init() {
 	usageMsg = rootCmd.UsageString()
 }
 
+// setLocations convert the --tz and --in-tz flags into library options
+// an empty "tz" keeps the local time zone; an empty "inTz" defaults to "tz"
+func setLocations(tz, inTz string) {
+	if len(tz) == 0 && len(inTz) == 0 {
+		return
+	}
+	parseLoc, err := dtdiff.LoadLocation(tz)
+	if err != nil {
+		fatal(codeInvalidTimeZone, err)
+	}
+	outputLoc := parseLoc
+	if len(inTz) > 0 {
+		outputLoc, err = dtdiff.LoadLocation(inTz)
+		if err != nil {
+			fatal(codeInvalidTimeZone, err)
+		}
+	}
+	calcOptions = append(calcOptions, dtdiff.WithParseLocation(parseLoc), dtdiff.WithOutputLocation(outputLoc))
+}
+
+// setClock convert the --now flag into a library option
+// without --now the current time is read once, so every result of one run, such as with -l or -C, uses the same instant
+func setClock(nowValue string) {
+	current := time.Now().Truncate(time.Second)
+	if len(nowValue) > 0 {
+		var err error
+		current, err = dtdiff.Parse(nowValue, calcOptions...)
+		if err != nil {
+			fatal(codeInvalidDate, fmt.Errorf("invalid --now value: %w", err))
+		}
+	}
+	calcOptions = append(calcOptions, dtdiff.WithClock(dtdiff.FixedClock(current)))
+}
+
+// setPrecision convert the --precision, --round and --truncate flags into library options
+func setPrecision(precision int, round, truncate bool) {
+	if precision < 0 {
+		fatal(codeInvalidInput, fmt.Errorf("invalid --precision: %d", precision))
+	}
+	if precision == 0 {
+		if round || truncate {
+			fatal(codeInvalidInput, fmt.Errorf("--round and --truncate need --precision"))
+		}
+		return
+	}
+	calcOptions = append(calcOptions, dtdiff.WithPrecision(precision))
+	if truncate {
+		calcOptions = append(calcOptions, dtdiff.WithTruncate())
+	}
+}
+
+// setUnits convert the --units, --max-unit and --min-unit flags into library options
+func setUnits(units, maxUnit, minUnit string) {
+	var opts []dtdiff.Option
+	if len(units) > 0 {
+		opts = append(opts, dtdiff.WithUnits(strings.Split(units, ",")...))
+	}
+	if len(maxUnit) > 0 {
+		opts = append(opts, dtdiff.WithMaxUnit(maxUnit))
+	}
+	if len(minUnit) > 0 {
+		opts = append(opts, dtdiff.WithMinUnit(minUnit))
+	}
+	if len(opts) == 0 {
+		return
+	}
+	if err := dtdiff.ValidateOptions(opts...); err != nil {
+		fatal(codeInvalidUnit, err)
+	}
+	calcOptions = append(calcOptions, opts...)
+}
+
+// setLocale convert the --lang and --lang-file flags into a library option
+// each file is registered first, so that --lang can use the language it defines
+func setLocale(lang, langFiles string) {
+	if len(langFiles) > 0 {
+		for _, path := range strings.Split(langFiles, ",") {
+			if _, err := dtdiff.LoadLocale(strings.TrimSpace(path)); err != nil {
+				fatal(codeInvalidLocale, err)
+			}
+		}
+	}
+	if len(lang) == 0 {
+		return
+	}
+	if _, err := dtdiff.LookupLocale(lang); err != nil {
+		fatal(codeInvalidLocale, err)
+	}
+	calcOptions = append(calcOptions, dtdiff.WithLocale(lang))
+}
+
+// setCalendar convert the --weekend and --holidays flags into a library option
+// the weekend given with --weekend overrides one found in a YAML holiday file
+func setCalendar(weekend, holidays string) {
+	if len(weekend) == 0 && len(holidays) == 0 {
+		return
+	}
+	var err error
+	calendar := dtdiff.NewCalendar()
+	if len(holidays) > 0 {
+		calendar, err = dtdiff.LoadCalendar(strings.Split(holidays, ",")...)
+		if err != nil {
+			fatal(codeInvalidCalendar, err)
+		}
+	}
+	if len(weekend) > 0 {
+		calendar.Weekend, err = dtdiff.ParseWeekend(weekend)
+		if err != nil {
+			fatal(codeInvalidCalendar, err)
+		}
+	}
+	calcOptions = append(calcOptions, dtdiff.WithCalendar(calendar))
+}
+
+// setLayout convert the --layout and --strftime flags into library options
+func setLayout(layout, strftime string) {
+	if len(layout) > 0 {
+		layoutOptions = append(layoutOptions, dtdiff.WithLayout(layout))
+	}
+	if len(strftime) > 0 {
+		layoutOptions = append(layoutOptions, dtdiff.WithStrftime(strftime))
+		if err := dtdiff.ValidateOptions(layoutOptions...); err != nil {
+			fatal(codeInvalidLayout, err)
+		}
+	}
+}
+
+// setPeriodOptions convert the --sum-units flag into a library option and
+// validate the -A or -S period once, before any dates are read
+func setPeriodOptions(sumUnits bool, periods ...string) {
+	if sumUnits {
+		calcOptions = append(calcOptions, dtdiff.WithRepeatedUnits())
+	}
+	for _, period := range periods {
+		if len(period) == 0 {
+			continue
+		}
+		if err := dtdiff.ValidatePeriod(period, calcOptions...); err != nil {
+			fatal(codeInvalidPeriod, err)
+		}
+	}
+}
+
+// textOptions return the library options used for text output
+func textOptions() []dtdiff.Option {
+	return append(append([]dtdiff.Option{}, calcOptions...), layoutOptions...)
+}
+
+// formatted return "result" when --layout or --strftime was given, otherwise an empty string
+func formatted(result string) string {
+	if len(layoutOptions) == 0 {
+		return ""
+	}
+	return result
+}
+
+// calculation compute the text output lines and the structured result for one input
+// "values" holds start and end for Flag Group 1, or "from" for Flag Group 2
+type calculation func(values []string) ([]string, interface{}, error)
+
+// selectCalculation return the calculation chosen by the command line flags along with
+// its input values; a nil calculation means that not enough flags were given
+func selectCalculation() (calculation, []string) {
+	if len(cron) > 0 {
+		if len(from) == 0 && !readFromStdin {
+			return nil, nil
+		}
+		return func(values []string) ([]string, interface{}, error) {
+			return computeCron(values[0], cron, recurrence, until)
+		}, []string{from}
+	}
+
+	if len(add) > 0 || len(sub) > 0 {
+		if len(from) == 0 && !readFromStdin {
+			return nil, nil
+		}
+		period, index := add, 0
+		if len(sub) > 0 {
+			period, index = sub, 1
+		}
+		return func(values []string) ([]string, interface{}, error) {
+			if emitInterval {
+				return computeFromInterval(values[0], period, index)
+			}
+			if recurrence > 0 {
+				return computeAddSubWithRecurrence(values[0], period, index, recurrence)
+			}
+			if len(until) > 0 {
+				return computeUntil(values[0], until, period, index)
+			}
+			return computeAddSub(values[0], period, index)
+		}, []string{from}
+	}
+
+	if (len(start) > 0 && len(end) > 0) || readFromStdin {
+		calc := computeStartEnd
+		if emitInterval {
+			calc = computeStartEndInterval
+		} else if businessDays {
+			calc = computeBusinessDays
+		} else if len(unit) > 0 {
+			calc = computeTotal
+		}
+		return func(values []string) ([]string, interface{}, error) {
+			return calc(values[0], values[1])
+		}, []string{start, end}
+	}
+	return nil, nil
+}
+
 // either read one line containing a comma, then split start and end on this
 // or read two lines with start on line one and end on line two
-func getInput() (string, string) {
+// when "fields" is 1, only one line containing "from" is read
+func getInput(fields int) []string {
 	input := bufio.NewScanner(os.Stdin)
 	input.Scan()
 	line := input.Text()
+	if fields == 1 {
+		return []string{line}
+	}
 	if strings.Contains(line, ",") {
-		split := strings.Split(line, ",")
-		if len(split) != 2 {
-			fmt.Fprintf(os.Stderr, "invalid stdin input: %s\n", line)
-			os.Exit(1)
+		split, err := splitFields(line, ',')
+		if err != nil || len(split) != 2 {
+			fatal(codeInvalidInput, fmt.Errorf("invalid stdin input: %s", line))
 		}
-		return split[0], split[1]
+		return split
 	}
 	input.Scan()
 	end := input.Text()
-	return line, end
+	return []string{line, end}
 }
 
-// computeStartEnd used when -s and -e is given
-func computeStartEnd(start, end string, brief bool) {
-	if readFromStdin {
-		start, end = getInput()
+// splitLine split one line of batch input into "fields" values
+// start and end are separated by a tab or a comma, quoted fields may contain commas
+func splitLine(line string, fields int) ([]string, error) {
+	if fields == 1 {
+		return []string{line}, nil
+	}
+	comma := ','
+	if strings.Contains(line, "\t") {
+		comma = '\t'
+	}
+	split, err := splitFields(line, comma)
+	if err != nil || len(split) != 2 {
+		return nil, newCodedError(codeInvalidInput, fmt.Errorf("invalid batch input, expected start%cend: %s", comma, line))
+	}
+	return split, nil
+}
+
+// runBatch apply calc to every non-blank line of STDIN and write one result per line
+// lines that fail are reported to STDERR along with their line number and processing
+// continues; the exit code is 1 when at least one line failed
+func runBatch(calc calculation, fields int) {
+	failed, err := processBatch(os.Stdin, os.Stdout, calc, fields, reportLine)
+	if err != nil {
+		fatal(codeInvalidInput, err)
 	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
 
-	dt := dtdiff.New(start, end)
+// processBatch apply calc to every non-blank line of r, write the results to w and
+// give each line that fails to "report"; return the number of lines that failed
+func processBatch(r io.Reader, out io.Writer, calc calculation, fields int, report func(number int, err error)) (int, error) {
+	input := bufio.NewScanner(r)
+	w := bufio.NewWriter(out)
+	failed := 0
+	for number := 1; input.Scan(); number++ {
+		line := strings.TrimSpace(input.Text())
+		if len(line) == 0 {
+			continue
+		}
+		values, err := splitLine(line, fields)
+		var lines []string
+		var result interface{}
+		if err == nil {
+			lines, result, err = calc(values)
+		}
+		if err != nil {
+			failed++
+			report(number, err)
+			continue
+		}
+		emitRecord(w, lines, result)
+	}
+	w.Flush()
+	return failed, input.Err()
+}
+
+// newDtDiff return a DtDiff for start and end configured with the global flags
+func newDtDiff(start, end string) *dtdiff.DtDiff {
+	opts := append([]dtdiff.Option{}, calcOptions...)
+	// --calendar-diff=false turns off the default for dates without a time
+	if calendarDiffSet {
+		opts = append(opts, dtdiff.WithCalendarDiff(calendarDiff))
+	}
+	dt, err := dtdiff.NewWithOptions(start, end, opts...)
+	if err != nil {
+		fatal(codeInvalidInput, err)
+	}
+	return dt
+}
+
+// newDiffResult return the structured output for dt, which includes the long, brief and ISO 8601 formats
+func newDiffResult(dt *dtdiff.DtDiff) (diffResult, error) {
+	dt.SetBrief(false)
+	dt.SetISO8601(false)
+	human, duration, err := dt.DtDiff()
+	if err != nil {
+		return diffResult{}, newCodedError(codeInvalidDate, err)
+	}
+	dt.SetBrief(true)
+	brief, _, _ := dt.DtDiff()
+	dt.SetISO8601(true)
+	iso, _, _ := dt.DtDiff()
+	return diffResult{
+		Start:      rfc3339(dt.StartTime()),
+		End:        rfc3339(dt.EndTime()),
+		DurationNs: int64(duration),
+		Human:      human,
+		Brief:      brief,
+		ISO8601:    iso,
+	}, nil
+}
+
+// computeStartEnd used when -s and -e is given
+func computeStartEnd(start, end string) ([]string, interface{}, error) {
+	dt := newDtDiff(start, end)
 	dt.SetBrief(brief)
+	dt.SetISO8601(iso8601)
 	format, _, err := dt.DtDiff()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		return nil, nil, newCodedError(codeInvalidDate, err)
+	}
+	if !structured() {
+		return []string{format}, nil, nil
+	}
+	result, err := newDiffResult(dt)
+	return []string{format}, result, err
+}
+
+// computeBusinessDays used when -B is given along with -s and -e
+func computeBusinessDays(start, end string) ([]string, interface{}, error) {
+	dt := newDtDiff(start, end)
+	count, err := dt.BusinessDays()
+	if err != nil {
+		return nil, nil, newCodedError(codeInvalidDate, err)
+	}
+
+	format := fmt.Sprintf("%d business days", count)
+	if count == 1 || count == -1 {
+		format = fmt.Sprintf("%d business day", count)
 	}
-	if noNewline {
-		fmt.Print(format)
-	} else {
-		fmt.Println(format)
+	if brief {
+		format = fmt.Sprintf("%dBD", count)
 	}
+	if !structured() {
+		return []string{format}, nil, nil
+	}
+	result, err := newDiffResult(dt)
+	result.BusinessDays = &count
+	return []string{format}, result, err
+}
+
+// computeTotal used when -u is given along with -s and -e
+func computeTotal(start, end string) ([]string, interface{}, error) {
+	dt := newDtDiff(start, end)
+	format, err := dt.Total(unit, decimals)
+	if err != nil {
+		return nil, nil, newCodedError(codeInvalidUnit, err)
+	}
+	if !structured() {
+		return []string{format}, nil, nil
+	}
+	result, err := newDiffResult(dt)
+	result.Unit = unit
+	result.Total = format
+	return []string{format}, result, err
+}
+
+// parsedFrom return "from" in RFC3339 format for structured output
+func parsedFrom(from string) (string, error) {
+	f, err := dtdiff.Parse(from, calcOptions...)
+	if err != nil {
+		return "", newCodedError(codeInvalidDate, err)
+	}
+	return rfc3339(f), nil
 }
 
 // computeAddSub used when -F is given along with
 // add or subtract a duration from "from"
 // index 0 = add; index = 1 = sub
-func computeAddSub(from, period string, index int) {
-	format := ""
-	var err error
-	if index == 0 {
-		format, err = dtdiff.Add(from, period)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
-	} else {
-		format, err = dtdiff.Sub(from, period)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
+func computeAddSub(from, period string, index int) ([]string, interface{}, error) {
+	calc := dtdiff.Add
+	if index == 1 {
+		calc = dtdiff.Sub
+	}
+	format, err := calc(from, period, textOptions()...)
+	if err != nil {
+		return nil, nil, newCodedError(codeInvalidPeriod, err)
+	}
+	if !structured() {
+		return []string{format}, nil, nil
 	}
 
-	if noNewline {
-		fmt.Print(format)
-	} else {
-		fmt.Println(format)
+	parsed, err := parsedFrom(from)
+	if err != nil {
+		return nil, nil, err
 	}
+	plain, _ := calc(from, period, calcOptions...)
+	result := calcResult{From: parsed, Operation: operationName(index), Period: period, Result: resultToRFC3339(plain), Formatted: formatted(format)}
+	return []string{format}, result, nil
 }
 
 // computeAddSubWithRecurrence is similar to computeAddSub
 // but returns a slice of date/time intervals
 // when -n is invoked, a comma-delimited output is used
 // index 0 = add; index = 1 = sub
-func computeAddSubWithRecurrence(from, period string, index, recurrence int) {
-	var format []string
-	var err error
-	if index == 0 {
-		format, err = dtdiff.AddWithRecurrence(from, period, recurrence)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
-	} else {
-		format, err = dtdiff.SubWithRecurrence(from, period, recurrence)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
+func computeAddSubWithRecurrence(from, period string, index, recurrence int) ([]string, interface{}, error) {
+	calc := dtdiff.AddWithRecurrence
+	if index == 1 {
+		calc = dtdiff.SubWithRecurrence
+	}
+	format, err := calc(from, period, recurrence, textOptions()...)
+	if err != nil {
+		return nil, nil, newCodedError(codeInvalidPeriod, err)
+	}
+	if !structured() {
+		return format, nil, nil
 	}
 
-	if noNewline {
-		fmt.Print(strings.Join(format, ","))
-	} else {
-		for _, f := range format {
-			fmt.Println(f)
-		}
+	plain, _ := calc(from, period, recurrence, calcOptions...)
+	result, err := newRecurrenceResult(from, period, index, plain, format)
+	result.Recurrence = recurrence
+	return format, result, err
+}
+
+// computeUntil is similar to computeAddSubWithRecurrence
+// but repeats the period until the 'until' date/time is exceeded
+func computeUntil(from, until, period string, index int) ([]string, interface{}, error) {
+	calc := dtdiff.AddUntil
+	if index == 1 {
+		calc = dtdiff.SubUntil
 	}
+	format, err := calc(from, until, period, textOptions()...)
+	if err != nil {
+		return nil, nil, newCodedError(codeInvalidPeriod, err)
+	}
+	if !structured() {
+		return format, nil, nil
+	}
+
+	plain, _ := calc(from, until, period, calcOptions...)
+	result, err := newRecurrenceResult(from, period, index, plain, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.Until, err = parsedFrom(until)
+	return format, result, err
 }
 
-func computeUntil(from, until, period string, index int) {
-	var format []string
-	var err error
-	if index == 0 {
-		format, err = dtdiff.AddUntil(from, until, period)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
-	} else {
-		format, err = dtdiff.SubUntil(from, until, period)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+// computeCron used when -F is given along with -j
+// list the next "recurrence" times of the cron expression, one when it is 0,
+// or every time until the 'until' date/time is exceeded
+func computeCron(from, expression string, recurrence int, until string) ([]string, interface{}, error) {
+	calc := func(opts []dtdiff.Option) ([]string, error) {
+		if len(until) > 0 {
+			return dtdiff.CronSeq(expression, from, until, opts...)
 		}
+		return dtdiff.CronNext(expression, from, max(recurrence, 1), opts...)
+	}
+	format, err := calc(textOptions())
+	if err != nil {
+		return nil, nil, newCodedError(codeInvalidCron, err)
+	}
+	if !structured() {
+		return format, nil, nil
 	}
 
-	if noNewline {
-		fmt.Print(strings.Join(format, ","))
-	} else {
-		for _, f := range format {
-			fmt.Println(f)
-		}
+	plain, _ := calc(calcOptions)
+	parsed, err := parsedFrom(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := cronResult{From: parsed, Cron: expression, Recurrence: recurrence, Results: []indexedResult{}}
+	for i, p := range plain {
+		result.Results = append(result.Results, indexedResult{Index: i + 1, Result: resultToRFC3339(p), Formatted: formatted(format[i])})
+	}
+	if len(until) > 0 {
+		result.Until, err = parsedFrom(until)
+	}
+	return format, result, err
+}
+
+// newRecurrenceResult return the structured output for a list of results
+// "all" is the default output of the library and "allFormatted" uses --layout or --strftime
+func newRecurrenceResult(from, period string, index int, all, allFormatted []string) (recurrenceResult, error) {
+	parsed, err := parsedFrom(from)
+	if err != nil {
+		return recurrenceResult{}, err
+	}
+	result := recurrenceResult{From: parsed, Operation: operationName(index), Period: period, Results: []indexedResult{}}
+	for i, a := range all {
+		result.Results = append(result.Results, indexedResult{Index: i + 1, Result: resultToRFC3339(a), Formatted: formatted(allFormatted[i])})
 	}
+	return result, nil
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSplitLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		fields  int
+		correct []string
+	}{
+		{"2024-01-01,2024-01-02", 2, []string{"2024-01-01", "2024-01-02"}},
+		{"2024-01-01 10:00:00\t2024-01-02 11:00:00", 2, []string{"2024-01-01 10:00:00", "2024-01-02 11:00:00"}},
+		// a tab is preferred, so a comma can be part of a date
+		{"Jan 2, 2024\tJan 3, 2024", 2, []string{"Jan 2, 2024", "Jan 3, 2024"}},
+		{`"Jan 2, 2024","Jan 3, 2024"`, 2, []string{"Jan 2, 2024", "Jan 3, 2024"}},
+		{"Jan 2, 2024", 1, []string{"Jan 2, 2024"}},
+		{"2024-01-01", 2, nil},
+		{"2024-01-01,2024-01-02,2024-01-03", 2, nil},
+		{"Jan 2, 2024,Jan 3, 2024", 2, nil},
+		{`"unterminated,2024-01-02`, 2, nil},
+	}
+	for _, test := range tests {
+		values, err := splitLine(test.line, test.fields)
+		if test.correct == nil {
+			if err == nil || errorCodeOf(err) != codeInvalidInput {
+				t.Errorf("[line: %v] [computed: %q %v] expected an %s error", test.line, values, err, codeInvalidInput)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if strings.Join(values, "|") != strings.Join(test.correct, "|") {
+			t.Errorf("[line: %v] [computed: %q] != [correct: %q]", test.line, values, test.correct)
+		}
+	}
+}
+
+func TestProcessBatch(t *testing.T) {
+	// echo the values, failing for "bad"
+	calc := func(values []string) ([]string, interface{}, error) {
+		if values[0] == "bad" {
+			return nil, nil, errors.New("bad value")
+		}
+		return []string{strings.Join(values, "|")}, map[string]string{"values": strings.Join(values, "|")}, nil
+	}
+	input := "a,b\n\n   \nbad,c\nd\te\nonly\n"
+
+	var failedLines []string
+	report := func(number int, err error) {
+		failedLines = append(failedLines, fmt.Sprintf("%d: %v", number, err))
+	}
+	var out bytes.Buffer
+	failed, err := processBatch(strings.NewReader(input), &out, calc, 2, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 2 {
+		t.Errorf("[computed: %v] != [correct: %v]", failed, 2)
+	}
+	// blank lines are skipped but still counted in the line numbers
+	correctFailed := "4: bad value; 6: invalid batch input, expected start,end: only"
+	if strings.Join(failedLines, "; ") != correctFailed {
+		t.Errorf("[computed: %v] != [correct: %v]", strings.Join(failedLines, "; "), correctFailed)
+	}
+	if out.String() != "a|b\nd|e\n" {
+		t.Errorf("[computed: %q] != [correct: %q]", out.String(), "a|b\nd|e\n")
+	}
+
+	// JSON Lines in structured mode
+	defer func(previous string) { output = previous }(output)
+	output = "json"
+	out.Reset()
+	if failed, _ = processBatch(strings.NewReader("a,b\nd,e\n"), &out, calc, 2, report); failed != 0 {
+		t.Errorf("[computed: %v] != [correct: %v]", failed, 0)
+	}
+	correct := "{\"values\":\"a|b\"}\n{\"values\":\"d|e\"}\n"
+	if out.String() != correct {
+		t.Errorf("[computed: %q] != [correct: %q]", out.String(), correct)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jftuga/dtdiff"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// resultLayout is the layout of the strings returned by Add, Sub and their variants
+const resultLayout string = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// error codes returned in structured output; these do not change between versions
+const (
+	codeInvalidInput      string = "invalid_input"
+	codeInvalidDate       string = "unparseable_date"
+	codeInvalidPeriod     string = "invalid_period"
+	codeDuplicateUnit     string = "duplicate_unit"
+	codeRangeOverflow     string = "range_overflow"
+	codeInvalidTimeZone   string = "invalid_time_zone"
+	codeInvalidCalendar   string = "invalid_calendar"
+	codeInvalidUnit       string = "invalid_unit"
+	codeInvalidLayout     string = "invalid_layout"
+	codeInvalidExpression string = "invalid_expression"
+	codeInvalidInterval   string = "invalid_interval"
+	codeInvalidLocale     string = "invalid_locale"
+	codeInvalidCron       string = "invalid_cron"
+	codeInvalidOutput     string = "invalid_output"
+)
+
+// exitCodes is the exit code of each error code; these do not change between versions
+// a batch or CSV file with at least one failed line always exits with 1
+var exitCodes = map[string]int{
+	codeInvalidInput:      1,
+	codeInvalidOutput:     2,
+	codeInvalidDate:       3,
+	codeInvalidPeriod:     4,
+	codeDuplicateUnit:     5,
+	codeRangeOverflow:     6,
+	codeInvalidTimeZone:   7,
+	codeInvalidCalendar:   8,
+	codeInvalidUnit:       9,
+	codeInvalidLayout:     10,
+	codeInvalidExpression: 11,
+	codeInvalidInterval:   12,
+	codeInvalidLocale:     13,
+	codeInvalidCron:       14,
+}
+
+// diffResult is the structured output of -s/-e
+type diffResult struct {
+	Start        string `json:"start" yaml:"start"`
+	End          string `json:"end" yaml:"end"`
+	DurationNs   int64  `json:"duration_ns" yaml:"duration_ns"`
+	Human        string `json:"human" yaml:"human"`
+	Brief        string `json:"brief" yaml:"brief"`
+	ISO8601      string `json:"iso8601" yaml:"iso8601"`
+	BusinessDays *int   `json:"business_days,omitempty" yaml:"business_days,omitempty"`
+	Unit         string `json:"unit,omitempty" yaml:"unit,omitempty"`
+	Total        string `json:"total,omitempty" yaml:"total,omitempty"`
+}
+
+// calcResult is the structured output of -F with -A or -S
+type calcResult struct {
+	From      string `json:"from" yaml:"from"`
+	Operation string `json:"operation" yaml:"operation"`
+	Period    string `json:"period" yaml:"period"`
+	Result    string `json:"result" yaml:"result"`
+	Formatted string `json:"formatted,omitempty" yaml:"formatted,omitempty"`
+}
+
+// indexedResult is one entry of a recurrence, starting at index 1
+type indexedResult struct {
+	Index     int    `json:"index" yaml:"index"`
+	Result    string `json:"result" yaml:"result"`
+	Formatted string `json:"formatted,omitempty" yaml:"formatted,omitempty"`
+}
+
+// recurrenceResult is the structured output of -F with -R or -U
+type recurrenceResult struct {
+	From       string          `json:"from" yaml:"from"`
+	Operation  string          `json:"operation" yaml:"operation"`
+	Period     string          `json:"period" yaml:"period"`
+	Recurrence int             `json:"recurrence,omitempty" yaml:"recurrence,omitempty"`
+	Until      string          `json:"until,omitempty" yaml:"until,omitempty"`
+	Results    []indexedResult `json:"results" yaml:"results"`
+}
+
+// cronResult is the structured output of -F with -j
+type cronResult struct {
+	From       string          `json:"from" yaml:"from"`
+	Cron       string          `json:"cron" yaml:"cron"`
+	Recurrence int             `json:"recurrence,omitempty" yaml:"recurrence,omitempty"`
+	Until      string          `json:"until,omitempty" yaml:"until,omitempty"`
+	Results    []indexedResult `json:"results" yaml:"results"`
+}
+
+// errorResult is the structured output of any failure
+// Line is the line number of the failing input in batch mode
+type errorResult struct {
+	Line  int         `json:"line,omitempty" yaml:"line,omitempty"`
+	Error errorDetail `json:"error" yaml:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// codedError is an error along with its structured output code
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// newCodedError wrap err with one of the structured output codes
+// the kind of a library error, such as dtdiff.ErrUnparseableDate, takes precedence over "code"
+func newCodedError(code string, err error) error {
+	return &codedError{code: libraryCode(code, err), err: err}
+}
+
+// libraryCode return the code matching the kind of a library error, otherwise "code"
+func libraryCode(code string, err error) string {
+	switch {
+	case errors.Is(err, dtdiff.ErrUnparseableDate):
+		return codeInvalidDate
+	case errors.Is(err, dtdiff.ErrDuplicateUnit):
+		return codeDuplicateUnit
+	case errors.Is(err, dtdiff.ErrRangeOverflow):
+		return codeRangeOverflow
+	case errors.Is(err, dtdiff.ErrInvalidPeriod):
+		return codeInvalidPeriod
+	case errors.Is(err, dtdiff.ErrInvalidCron):
+		return codeInvalidCron
+	case errors.Is(err, dtdiff.ErrInvalidCalendar):
+		return codeInvalidCalendar
+	case errors.Is(err, dtdiff.ErrInvalidExpression):
+		return codeInvalidExpression
+	}
+	return code
+}
+
+// errorCodeOf return the structured output code of err, defaulting to codeInvalidInput
+func errorCodeOf(err error) string {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return codeInvalidInput
+}
+
+// structured return true when --output is json or yaml
+func structured() bool {
+	return output == "json" || output == "yaml"
+}
+
+// validateOutput ensure --output is one of: text, json, yaml
+func validateOutput() {
+	output = strings.ToLower(output)
+	switch output {
+	case "", "text":
+		output = "text"
+	case "json", "yaml":
+	default:
+		invalid := output
+		output = "text"
+		fatal(codeInvalidOutput, fmt.Errorf("invalid output format: %s; use text, json or yaml", invalid))
+	}
+}
+
+// emit write "text" or the structured "result", depending on --output
+func emit(text string, result interface{}) {
+	if structured() {
+		text = marshal(result)
+	}
+	if noNewline {
+		fmt.Print(text)
+	} else {
+		fmt.Println(text)
+	}
+}
+
+// emitLines is similar to emit, but writes one line per entry of "lines"
+// when -n is invoked, a comma-delimited output is used
+func emitLines(lines []string, result interface{}) {
+	if structured() || noNewline {
+		emit(strings.Join(lines, ","), result)
+		return
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// emitRecord write one batch result to w; text results use one line per entry
+// of "lines", JSON results use one compact object per line (JSON Lines)
+// and YAML results are written as separate documents
+func emitRecord(w io.Writer, lines []string, result interface{}) {
+	switch output {
+	case "json":
+		data, err := json.Marshal(result)
+		if err != nil {
+			fatal(codeInvalidInput, err)
+		}
+		fmt.Fprintln(w, string(data))
+	case "yaml":
+		fmt.Fprintf(w, "---\n%s\n", marshal(result))
+	default:
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// reportLine write the error of one batch input line to STDERR
+// in structured mode, the error is written as a single line of JSON or YAML
+func reportLine(number int, err error) {
+	if !structured() {
+		fmt.Fprintf(os.Stderr, "line %d: %v\n", number, err)
+		return
+	}
+	result := errorResult{Line: number, Error: errorDetail{Code: errorCodeOf(err), Message: err.Error()}}
+	data, _ := json.Marshal(result)
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// marshal convert "result" to JSON or YAML without a trailing newline
+func marshal(result interface{}) string {
+	var data []byte
+	var err error
+	if output == "yaml" {
+		data, err = yaml.Marshal(result)
+	} else {
+		data, err = json.MarshalIndent(result, "", "  ")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return strings.TrimRight(string(data), "\n")
+}
+
+// fatal report err and exit; in structured mode the error is written to STDOUT
+// as an object with a stable "code", otherwise the message is written to STDERR
+// the exit code depends on the error code, see exitCodes
+func fatal(code string, err error) {
+	code = libraryCode(code, err)
+	if structured() {
+		emit("", errorResult{Error: errorDetail{Code: code, Message: err.Error()}})
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	exitCode, ok := exitCodes[code]
+	if !ok {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
+
+// rfc3339 convert a time to RFC3339 with as many fractional digits as needed
+func rfc3339(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// resultToRFC3339 convert a string returned by Add or Sub to RFC3339
+func resultToRFC3339(result string) string {
+	t, err := time.Parse(resultLayout, result)
+	if err != nil {
+		return result
+	}
+	return rfc3339(t)
+}
+
+// operationName return "add" for index 0 and "sub" for index 1
+func operationName(index int) string {
+	if index == 0 {
+		return "add"
+	}
+	return "sub"
+}
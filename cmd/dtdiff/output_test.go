@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jftuga/dtdiff"
+	"testing"
+	"time"
+)
+
+// TestExitCodes pin the exit code of every error code; these must not change between versions
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		code    string
+		correct int
+	}{
+		{"invalid_input", 1},
+		{"invalid_output", 2},
+		{"unparseable_date", 3},
+		{"invalid_period", 4},
+		{"duplicate_unit", 5},
+		{"range_overflow", 6},
+		{"invalid_time_zone", 7},
+		{"invalid_calendar", 8},
+		{"invalid_unit", 9},
+		{"invalid_layout", 10},
+		{"invalid_expression", 11},
+		{"invalid_interval", 12},
+		{"invalid_locale", 13},
+		{"invalid_cron", 14},
+	}
+	if len(exitCodes) != len(tests) {
+		t.Errorf("[computed: %v] != [correct: %v]", len(exitCodes), len(tests))
+	}
+	for _, test := range tests {
+		computed, ok := exitCodes[test.code]
+		if !ok || computed != test.correct {
+			t.Errorf("[code: %v] [computed: %v] != [correct: %v]", test.code, computed, test.correct)
+		}
+	}
+}
+
+// TestErrorCodes map each kind of library error to its stable error code and exit code
+func TestErrorCodes(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	failure := func(_ interface{}, err error) error { return err }
+	tests := []struct {
+		name    string
+		err     error
+		code    string
+		correct string
+		exit    int
+	}{
+		{"unparseable date", failure(dtdiff.Parse("not a date")), codeInvalidInput, "unparseable_date", 3},
+		{"invalid period", failure(dtdiff.ParsePeriod("1Q")), codeInvalidInput, "invalid_period", 4},
+		{"duplicate unit", failure(dtdiff.ParsePeriod("1D2D")), codeInvalidPeriod, "duplicate_unit", 5},
+		{"range overflow", failure(dtdiff.AddTime(from, dtdiff.Period{Years: 2000000000})), codeInvalidInput, "range_overflow", 6},
+		{"invalid calendar", failure(dtdiff.ParseWeekend("sat,funday")), codeInvalidInput, "invalid_calendar", 8},
+		{"invalid expression", failure(dtdiff.Eval("2024-01-01 +")), codeInvalidInput, "invalid_expression", 11},
+		{"invalid cron", failure(dtdiff.ParseCron("61 * * * *")), codeInvalidInput, "invalid_cron", 14},
+		{"wrapped sentinel", fmt.Errorf("line 3: %w", dtdiff.ErrRangeOverflow), codeInvalidInput, "range_overflow", 6},
+		{"other error", errors.New("unknown time zone"), codeInvalidTimeZone, "invalid_time_zone", 7},
+		{"uncoded error", errors.New("plain"), "", "invalid_input", 1},
+	}
+	for _, test := range tests {
+		if test.err == nil {
+			t.Errorf("[%v] expected an error", test.name)
+			continue
+		}
+		err := test.err
+		if test.code != "" {
+			err = newCodedError(test.code, test.err)
+		}
+		computed := errorCodeOf(fmt.Errorf("wrapped: %w", err))
+		if computed != test.correct {
+			t.Errorf("[%v] [computed: %v] != [correct: %v]", test.name, computed, test.correct)
+		}
+		if exitCodes[computed] != test.exit {
+			t.Errorf("[%v] [computed: %v] != [correct: %v]", test.name, exitCodes[computed], test.exit)
+		}
+	}
+}
+
+func TestErrorResultJSON(t *testing.T) {
+	err := newCodedError(codeInvalidInput, fmt.Errorf("bad: %w", dtdiff.ErrUnparseableDate))
+	result := errorResult{Line: 2, Error: errorDetail{Code: errorCodeOf(err), Message: err.Error()}}
+	data, jsonErr := json.Marshal(result)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	correct := `{"line":2,"error":{"code":"unparseable_date","message":"bad: unparseable date"}}`
+	if string(data) != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", string(data), correct)
+	}
+
+	// line is omitted outside of batch mode
+	data, _ = json.Marshal(errorResult{Error: result.Error})
+	correct = `{"error":{"code":"unparseable_date","message":"bad: unparseable date"}}`
+	if string(data) != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", string(data), correct)
+	}
+}
@@ -0,0 +1,507 @@
+package dtdiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronYears is how far past the starting time a cron expression is searched for a match;
+// February only has a fifth Friday about every 28 years
+const cronYears = 50
+
+// cronMacros are the shortcuts accepted instead of the fields of a cron expression
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonths = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronWeekdays = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// the fields of a cron expression, always including seconds; see cronSpecs
+const (
+	cronSecond = iota
+	cronMinute
+	cronHour
+	cronDay
+	cronMonth
+	cronWeekday
+)
+
+// cronSpecs are the names and ranges of the fields of a cron expression, along with any names
+// accepted in place of numbers; 7 is also Sunday in the day of week field
+var cronSpecs = []struct {
+	name     string
+	min, max int
+	names    map[string]int
+}{
+	{"second", 0, 59, nil},
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, cronMonths},
+	{"day of week", 0, 7, cronWeekdays},
+}
+
+// cronField is the set of values allowed by one field of a cron expression, one bit for each value
+type cronField uint64
+
+// has return true when v is allowed
+func (f cronField) has(v int) bool {
+	return f&(1<<uint(v)) != 0
+}
+
+// allHours allows every hour of the day
+const allHours cronField = 1<<24 - 1
+
+// nthWeekday is the "n"th "weekday" of the month, written as weekday#n, such as 5#3 for the third Friday
+type nthWeekday struct {
+	weekday int
+	n       int
+}
+
+// CronSchedule is a parsed cron expression, see ParseCron
+type CronSchedule struct {
+	expression                                      string
+	seconds, minutes, hours, days, months, weekdays cronField
+	// set by a CRON_TZ= or TZ= prefix; nil uses the location of the starting time
+	loc *time.Location
+	// true when the day of month or day of week field is "*" or "?", see matchDay
+	anyDay, anyWeekday bool
+	// L and L-n: the number of days before the last day of the month
+	lastDays []int
+	// LW: the weekday nearest to the last day of the month
+	lastWeekday bool
+	// nW: the weekday nearest to day n of the month
+	nearestWeekdays []int
+	// nL: the last weekday n of the month
+	lastWeekdays cronField
+	// n#k: the k-th weekday n of the month
+	nthWeekdays []nthWeekday
+}
+
+// cronToken is one field of a cron expression along with its offset within the expression
+type cronToken struct {
+	text   string
+	offset int
+}
+
+// ParseCron parse a cron expression with 5 fields: minute, hour, day of month, month and day of week,
+// or with 6 fields starting with the second, such as "*/15 9-17 * * MON-FRI" or "30 0 12 * * *"
+//
+// each field accepts *, numbers, names such as JAN or MON, ranges such as 1-5, steps such as */15 or 10-30/5,
+// and lists of these such as 1,15,L; ? is the same as * in the day of month and day of week fields
+// the day of month field also accepts L for the last day, L-n for n days before it, nW for the weekday nearest
+// to day n and LW for the last weekday; the day of week field accepts nL for the last weekday n of the month
+// and n#k for the k-th weekday n of the month, such as 5L or FRI#3
+// when both the day of month and day of week are restricted, a day matching either of them is used
+//
+// the macros @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are also accepted,
+// and a CRON_TZ= or TZ= prefix, such as "CRON_TZ=Europe/Berlin 0 9 * * *", gives the time zone of the schedule
+func ParseCron(expression string) (*CronSchedule, error) {
+	s := &CronSchedule{expression: expression}
+	tokens := cronTokens(expression)
+	if len(tokens) > 0 {
+		if name, ok := cutTimeZone(tokens[0].text); ok {
+			loc, err := LoadLocation(name)
+			if err != nil {
+				return nil, cronError("ParseCron", expression, tokens[0].offset, fmt.Sprintf("unknown time zone %q", name))
+			}
+			s.loc = loc
+			tokens = tokens[1:]
+		}
+	}
+	if len(tokens) == 1 && strings.HasPrefix(tokens[0].text, "@") {
+		macro, ok := cronMacros[strings.ToLower(tokens[0].text)]
+		if !ok {
+			return nil, cronError("ParseCron", expression, tokens[0].offset, fmt.Sprintf("unknown macro %q", tokens[0].text))
+		}
+		offset := tokens[0].offset
+		tokens = nil
+		for _, field := range strings.Fields(macro) {
+			tokens = append(tokens, cronToken{text: field, offset: offset})
+		}
+	}
+
+	switch len(tokens) {
+	case 5:
+		tokens = append([]cronToken{{text: "0"}}, tokens...)
+	case 6:
+	default:
+		return nil, cronError("ParseCron", expression, -1, fmt.Sprintf("expected 5 or 6 fields, found %d", len(tokens)))
+	}
+	for i, token := range tokens {
+		if err := s.parseField(i, token.text); err != nil {
+			return nil, cronError("ParseCron", expression, token.offset, fmt.Sprintf("%s field %q: %v", cronSpecs[i].name, token.text, err))
+		}
+	}
+	return s, nil
+}
+
+// cronTokens split a cron expression on whitespace, keeping the offset of each field
+func cronTokens(expression string) []cronToken {
+	var tokens []cronToken
+	start := -1
+	for i, r := range expression + " " {
+		if r == ' ' || r == '\t' {
+			if start >= 0 {
+				tokens = append(tokens, cronToken{text: expression[start:i], offset: start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// cutTimeZone return the time zone of a CRON_TZ= or TZ= prefix
+func cutTimeZone(token string) (string, bool) {
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if strings.HasPrefix(token, prefix) {
+			return token[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+// parseField parse the comma-separated items of field "i", see cronSpecs
+func (s *CronSchedule) parseField(i int, text string) error {
+	var field cronField
+	for _, item := range strings.Split(text, ",") {
+		ok, err := s.parseSpecial(i, strings.ToUpper(item))
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		bits, err := parseCronItem(i, item)
+		if err != nil {
+			return err
+		}
+		field |= bits
+	}
+
+	switch i {
+	case cronSecond:
+		s.seconds = field
+	case cronMinute:
+		s.minutes = field
+	case cronHour:
+		s.hours = field
+	case cronDay:
+		s.days = field
+		s.anyDay = strings.HasPrefix(text, "*") || text == "?"
+	case cronMonth:
+		s.months = field
+	case cronWeekday:
+		// 7 is also Sunday
+		if field.has(7) {
+			field = field&^(1<<7) | 1
+		}
+		s.weekdays = field
+		s.anyWeekday = strings.HasPrefix(text, "*") || text == "?"
+	}
+	return nil
+}
+
+// parseSpecial parse the L, W and # items of the day of month and day of week fields
+// and return false for any other item
+func (s *CronSchedule) parseSpecial(i int, item string) (bool, error) {
+	switch {
+	case i == cronDay && item == "L":
+		s.lastDays = append(s.lastDays, 0)
+	case i == cronDay && strings.HasPrefix(item, "L-"):
+		n, err := strconv.Atoi(item[2:])
+		if err != nil || n < 0 || n > 30 {
+			return false, fmt.Errorf("invalid offset from the last day: %s", item[2:])
+		}
+		s.lastDays = append(s.lastDays, n)
+	case i == cronDay && item == "LW":
+		s.lastWeekday = true
+	case i == cronDay && strings.HasSuffix(item, "W"):
+		n, err := cronValue(cronDay, item[:len(item)-1])
+		if err != nil {
+			return false, err
+		}
+		s.nearestWeekdays = append(s.nearestWeekdays, n)
+	case i == cronWeekday && len(item) > 1 && strings.HasSuffix(item, "L"):
+		n, err := cronValue(cronWeekday, item[:len(item)-1])
+		if err != nil {
+			return false, err
+		}
+		s.lastWeekdays |= 1 << uint(n%7)
+	case i == cronWeekday && strings.Contains(item, "#"):
+		weekday, nth, _ := strings.Cut(item, "#")
+		n, err := cronValue(cronWeekday, weekday)
+		if err != nil {
+			return false, err
+		}
+		k, err := strconv.Atoi(nth)
+		if err != nil || k < 1 || k > 5 {
+			return false, fmt.Errorf("invalid occurrence: %s; use 1 to 5", nth)
+		}
+		s.nthWeekdays = append(s.nthWeekdays, nthWeekday{weekday: n % 7, n: k})
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// parseCronItem return the values of a range with an optional step in field "i",
+// such as *, 5, MON-FRI, */15 or 10-30/5; a single value with a step continues to the end of the field
+func parseCronItem(i int, item string) (cronField, error) {
+	spec := cronSpecs[i]
+	base, stepText, hasStep := strings.Cut(item, "/")
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepText)
+		if err != nil || step < 1 {
+			return 0, fmt.Errorf("invalid step: %s", stepText)
+		}
+	}
+
+	var low, high int
+	switch {
+	case base == "*" || (base == "?" && (i == cronDay || i == cronWeekday)):
+		low, high = spec.min, spec.max
+		if i == cronWeekday {
+			high = 6
+		}
+	case strings.Contains(base, "-"):
+		first, last, _ := strings.Cut(base, "-")
+		var err error
+		if low, err = cronValue(i, first); err != nil {
+			return 0, err
+		}
+		if high, err = cronValue(i, last); err != nil {
+			return 0, err
+		}
+		if low > high {
+			return 0, fmt.Errorf("%s is after %s", first, last)
+		}
+	default:
+		var err error
+		if low, err = cronValue(i, base); err != nil {
+			return 0, err
+		}
+		high = low
+		if hasStep {
+			high = spec.max
+		}
+	}
+
+	var field cronField
+	for v := low; v <= high; v += step {
+		field |= 1 << uint(v)
+	}
+	return field, nil
+}
+
+// cronValue return the number or name "text" as a value of field "i", checking its range
+func cronValue(i int, text string) (int, error) {
+	spec := cronSpecs[i]
+	if v, ok := spec.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value: %s", text)
+	}
+	if v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("%d is out of range; use %d to %d", v, spec.min, spec.max)
+	}
+	return v, nil
+}
+
+// String return the cron expression of s
+func (s *CronSchedule) String() string {
+	return s.expression
+}
+
+// Next return the first time after t matched by the schedule, in the time zone of the expression,
+// or otherwise in the location of t; it fails when nothing matches within 50 years, such as for "0 0 30 2 *"
+//
+// wall clock times skipped by a daylight saving change are not matched, and when the clocks go back, a schedule
+// with a specific hour only matches the repeated times once, while one matching every hour matches both
+func (s *CronSchedule) Next(t time.Time) (time.Time, error) {
+	if s.loc != nil {
+		t = t.In(s.loc)
+	}
+	from := t
+	limit := t.Year() + cronYears
+	t = t.Truncate(time.Second).Add(time.Second)
+	for t.Year() <= limit {
+		switch {
+		case !s.months.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.hours.has(t.Hour()):
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second)
+		case !s.minutes.has(t.Minute()):
+			t = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+		case !s.seconds.has(t.Second()):
+			t = t.Add(time.Second)
+		case s.hours != allHours && !wallClock(t).After(wallClock(from)):
+			// a wall clock time repeated after the clocks go back
+			t = t.Add(time.Second)
+		default:
+			return t, nil
+		}
+	}
+	detail := fmt.Sprintf("no time matches within %d years of %s", cronYears, from.Format(time.RFC3339))
+	return time.Time{}, cronError("CronNext", s.expression, -1, detail)
+}
+
+// wallClock return the date and time of day of t, without its location
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// matchDay return true when the day of t is matched by the day of month and day of week fields;
+// when neither of them is "*" or "?", matching either one is enough
+func (s *CronSchedule) matchDay(t time.Time) bool {
+	day, weekday := s.matchMonthDay(t), s.matchWeekday(t)
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// matchMonthDay return true when the day of t is matched by the day of month field
+func (s *CronSchedule) matchMonthDay(t time.Time) bool {
+	if s.days.has(t.Day()) {
+		return true
+	}
+	last := daysInMonth(t)
+	for _, n := range s.lastDays {
+		if t.Day() == last-n {
+			return true
+		}
+	}
+	if s.lastWeekday && t.Day() == nearestWeekday(t, last) {
+		return true
+	}
+	for _, n := range s.nearestWeekdays {
+		if n <= last && t.Day() == nearestWeekday(t, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWeekday return true when the day of t is matched by the day of week field
+func (s *CronSchedule) matchWeekday(t time.Time) bool {
+	weekday := int(t.Weekday())
+	if s.weekdays.has(weekday) {
+		return true
+	}
+	if s.lastWeekdays.has(weekday) && t.Day()+7 > daysInMonth(t) {
+		return true
+	}
+	for _, nth := range s.nthWeekdays {
+		if nth.weekday == weekday && (t.Day()-1)/7+1 == nth.n {
+			return true
+		}
+	}
+	return false
+}
+
+// daysInMonth return the number of days in the month of t
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday return the Monday to Friday nearest to "day" in the month of t, without leaving the month
+func nearestWeekday(t time.Time, day int) int {
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == daysInMonth(t) {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// times return up to "count" times after t matched by the schedule, or when "bounded" is true,
+// every time until "until" is exceeded
+func (s *CronSchedule) times(t time.Time, count int, until time.Time, bounded bool) ([]time.Time, error) {
+	var all []time.Time
+	for bounded || len(all) < count {
+		next, err := s.Next(t)
+		if err != nil {
+			if bounded && len(all) > 0 {
+				break
+			}
+			return nil, err
+		}
+		if bounded && next.After(until) {
+			break
+		}
+		all = append(all, next)
+		t = next
+	}
+	return all, nil
+}
+
+// CronNext return the next "count" times after "from" matched by the cron "expression", see ParseCron
+// such as "*/15 9-17 * * MON-FRI" for every quarter hour during business hours
+func CronNext(expression, from string, count int, opts ...Option) ([]string, error) {
+	return cronSeq(expression, from, "", count, opts)
+}
+
+// CronSeq return every time after "from" matched by the cron "expression" until "until" is exceeded
+func CronSeq(expression, from, until string, opts ...Option) ([]string, error) {
+	return cronSeq(expression, from, until, 0, opts)
+}
+
+// cronSeq return the times of CronNext, or of CronSeq when "until" is given
+func cronSeq(expression, from, until string, count int, opts []Option) ([]string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseCron(expression)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseFrom(from, FieldFrom, o)
+	if err != nil {
+		return nil, err
+	}
+
+	var u time.Time
+	bounded := len(until) > 0
+	if bounded {
+		parsed, err := parseFrom(until, FieldUntil, o)
+		if err != nil {
+			return nil, err
+		}
+		u = parsed.StdTime()
+	}
+	all, err := s.times(f.StdTime(), count, u, bounded)
+	if err != nil {
+		return nil, err
+	}
+	return formatTimes(all, o), nil
+}
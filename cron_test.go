@@ -0,0 +1,108 @@
+package dtdiff
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	utc := WithLocation(time.UTC)
+	tests := []struct {
+		expression string
+		from       string
+		correct    []string
+	}{
+		{"*/15 9-17 * * MON-FRI", "2024-01-05 17:40:00", []string{"2024-01-05 17:45:00", "2024-01-08 09:00:00", "2024-01-08 09:15:00"}},
+		{"30 0 12 * * *", "2024-01-01 12:00:30", []string{"2024-01-02 12:00:30", "2024-01-03 12:00:30", "2024-01-04 12:00:30"}},
+		{"0 0 L * *", "2024-01-31 00:00:00", []string{"2024-02-29 00:00:00", "2024-03-31 00:00:00", "2024-04-30 00:00:00"}},
+		{"0 0 L-1 * *", "2024-01-01 00:00:00", []string{"2024-01-30 00:00:00", "2024-02-28 00:00:00", "2024-03-30 00:00:00"}},
+		{"0 9 LW * *", "2024-03-01 00:00:00", []string{"2024-03-29 09:00:00", "2024-04-30 09:00:00", "2024-05-31 09:00:00"}},
+		{"0 9 1W,15W * *", "2024-06-01 00:00:00", []string{"2024-06-03 09:00:00", "2024-06-14 09:00:00", "2024-07-01 09:00:00"}},
+		{"0 9 * * 5L", "2024-01-01 00:00:00", []string{"2024-01-26 09:00:00", "2024-02-23 09:00:00", "2024-03-29 09:00:00"}},
+		{"0 9 ? * fri#3", "2024-01-01 00:00:00", []string{"2024-01-19 09:00:00", "2024-02-16 09:00:00", "2024-03-15 09:00:00"}},
+		{"0 0 1,15 * MON", "2024-01-01 00:00:00", []string{"2024-01-08 00:00:00", "2024-01-15 00:00:00", "2024-01-22 00:00:00"}},
+		{"0 0 * JAN-MAR/2 7", "2024-01-27 00:00:00", []string{"2024-01-28 00:00:00", "2024-03-03 00:00:00", "2024-03-10 00:00:00"}},
+		{"0 0 29 2 *", "2024-01-01 00:00:00", []string{"2024-02-29 00:00:00", "2028-02-29 00:00:00", "2032-02-29 00:00:00"}},
+		{"@monthly", "2024-01-15 00:00:00", []string{"2024-02-01 00:00:00", "2024-03-01 00:00:00", "2024-04-01 00:00:00"}},
+		{"CRON_TZ=America/New_York 0 9 * * *", "2024-07-01 12:00:00", []string{"2024-07-01 13:00:00", "2024-07-02 13:00:00", "2024-07-03 13:00:00"}},
+	}
+	for _, test := range tests {
+		all, err := CronNext(test.expression, test.from, len(test.correct), utc)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for i := range all {
+			all[i] = strings.TrimSuffix(all[i], " +0000 UTC")
+		}
+		if strings.Join(all, ", ") != strings.Join(test.correct, ", ") {
+			t.Errorf("[expression: %v] [computed: %v] != [correct: %v]", test.expression, all, test.correct)
+		}
+	}
+}
+
+func TestCronSeq(t *testing.T) {
+	loc, err := LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expression string
+		from       string
+		until      string
+		correct    int
+	}{
+		{"*/15 * * * *", "2024-01-01 00:00:00", "2024-01-01 01:00:00", 4},
+		// the clocks go back at 2:00, so 1:00 to 1:59 happens twice
+		{"30 1 * * *", "2024-11-02 12:00:00", "2024-11-03 12:00:00", 1},
+		{"30 * * * *", "2024-11-03 00:00:00", "2024-11-03 03:00:00", 4},
+		// the clocks go forward at 2:00, so 2:30 never happens
+		{"30 2 * * *", "2024-03-09 12:00:00", "2024-03-10 12:00:00", 0},
+	}
+	for _, test := range tests {
+		all, err := CronSeq(test.expression, test.from, test.until, WithLocation(loc))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if len(all) != test.correct {
+			t.Errorf("[expression: %v] [computed: %v] != [correct: %v]", test.expression, all, test.correct)
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expression string
+		position   int
+	}{
+		{"61 * * * *", 0},
+		{"* * *", -1},
+		{"0 0 * * 8", 8},
+		{"5-1 * * * *", 0},
+		{"*/0 * * * *", 0},
+		{"? * * * *", 0},
+		{"0 0 L-31 * *", 4},
+		{"0 0 * * 5#6", 8},
+		{"CRON_TZ=Nowhere 0 0 * * *", 0},
+		{"@often", 0},
+	}
+	for _, test := range tests {
+		_, err := ParseCron(test.expression)
+		var inputErr *InputError
+		if !errors.Is(err, ErrInvalidCron) || !errors.As(err, &inputErr) {
+			t.Errorf("[expression: %v] [computed: %v] is not: [correct: %v]", test.expression, err, ErrInvalidCron)
+			continue
+		}
+		if inputErr.Position != test.position || inputErr.Field != FieldCron {
+			t.Errorf("[expression: %v] [computed: %v] != [correct: %v]", test.expression, inputErr.Position, test.position)
+		}
+	}
+
+	// February never has 30 days
+	if _, err := CronNext("0 0 30 2 *", "2024-01-01", 1); !errors.Is(err, ErrInvalidCron) {
+		t.Errorf("[computed: %v] is not: [correct: %v]", err, ErrInvalidCron)
+	}
+}
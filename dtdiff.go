@@ -5,8 +5,7 @@ import (
 	"github.com/golang-module/carbon/v2"
 	"github.com/hako/durafmt"
 	"github.com/jinzhu/now"
-	"regexp"
-	"strconv"
+	"math"
 	"strings"
 	"time"
 )
@@ -17,11 +16,9 @@ const (
 	PgmUrl     string = "https://github.com/jftuga/dtdiff"
 )
 
-const (
-	expanded  string = `(\d+)\s(years?|months?|weeks?|days?|hours?|minutes?|seconds?|milliseconds?|microseconds?|nanoseconds?)`
-	wordsOnly string = `\b[a-zA-Z]+\b`
-	dupMsg    string = "Hint: duplicate durations not allowed; dates in uppercase; times in lowercase"
-)
+// businessDay is handled by addBusinessDays instead of carbonFuncs
+// because it depends on which days are part of the weekend
+const businessDay string = "businessday"
 
 var carbonFuncs = map[string]interface{}{
 	"year":        [2]interface{}{carbon.Carbon.AddYears, carbon.Carbon.SubYears},
@@ -36,27 +33,81 @@ var carbonFuncs = map[string]interface{}{
 	"nanosecond":  [2]interface{}{carbon.Carbon.AddNanoseconds, carbon.Carbon.SubNanoseconds},
 }
 
-var expandedRegexp = regexp.MustCompile(expanded)
-
 type DtDiff struct {
 	Start string
 	End   string
 	Diff  time.Duration
 	Brief bool
+	// ISO8601 takes precedence over Brief
+	ISO8601 bool
+	// Precision is the number of non-zero units to keep, 0 keeps every unit
+	Precision int
+	// Truncate drops the units beyond Precision instead of rounding them
+	Truncate bool
+	opts     options
+	// set by dur
+	startTime time.Time
+	endTime   time.Time
 }
 
 func New(start, end string) *DtDiff {
-	start = convertRelativeDateToActual(start)
-	end = convertRelativeDateToActual(end)
 	return &DtDiff{Start: start, End: end, Diff: 0, Brief: false}
 }
 
+// NewWithOptions is similar to New, but configured with options such as WithBrief, WithLocation,
+// WithClock, WithLayouts, WithUnits or WithCalendarDiff; the first invalid option is returned as an error
+// the clock is read each time the difference is calculated, not when the DtDiff is created
+func NewWithOptions(start, end string, opts ...Option) (*DtDiff, error) {
+	o := applyOptions(opts)
+	if o.err != nil {
+		return nil, o.err
+	}
+	return &DtDiff{Start: start, End: end, Brief: o.brief, ISO8601: o.iso8601, Precision: o.precision, Truncate: o.truncate, opts: o}, nil
+}
+
 // SetBrief toggle brief output when using -s/e
 // this returns durations such as "1h2m3s" instead of "1 hour 2 minutes 3 seconds"
 func (dt *DtDiff) SetBrief(brief bool) {
 	dt.Brief = brief
 }
 
+// SetISO8601 toggle ISO 8601 output when using -s/e
+// this returns durations such as "PT1H2M3S" instead of "1 hour 2 minutes 3 seconds"
+func (dt *DtDiff) SetISO8601(iso bool) {
+	dt.ISO8601 = iso
+}
+
+// SetLocation interpret start and end in the "parse" location
+// and report them in the "output" location; a nil location means time.Local
+func (dt *DtDiff) SetLocation(parse, output *time.Location) {
+	dt.opts.parseLoc = parse
+	dt.opts.outputLoc = output
+}
+
+// SetCalendar use the weekends and holidays of cal when counting business days
+func (dt *DtDiff) SetCalendar(cal *Calendar) {
+	dt.opts.calendar = cal
+}
+
+// SetCalendarDiff toggle walking the actual calendar between start and end
+// this returns true years, months and days, such as "1 month 1 day" for 2024-01-31 to 2024-03-03,
+// so that Add(start, result) == end; it is the default when both start and end are dates without a time
+func (dt *DtDiff) SetCalendarDiff(calendarDiff bool) {
+	dt.opts.calendarDiff = &calendarDiff
+}
+
+// StartTime return the parsed start, in the output location when one was set
+// this is only valid after calling DtDiff, Total or BusinessDays
+func (dt *DtDiff) StartTime() time.Time {
+	return dt.opts.outputTime(dt.startTime)
+}
+
+// EndTime return the parsed end, in the output location when one was set
+// this is only valid after calling DtDiff, Total or BusinessDays
+func (dt *DtDiff) EndTime() time.Time {
+	return dt.opts.outputTime(dt.endTime)
+}
+
 // String return a DtDiff struct in string format
 func (dt *DtDiff) String() string {
 	return fmt.Sprintf("start:%v end:%v duration:%v brief:%v", dt.Start, dt.End, dt.Diff, dt.Brief)
@@ -65,41 +116,92 @@ func (dt *DtDiff) String() string {
 // dur return the time difference and also set dt.Diff
 // first try to parse with carbon, fallback to parsing with now if carbon fails to parse
 func (dt *DtDiff) dur() (time.Duration, error) {
-	var err error
-	var start, end time.Time
+	start, end, err := dt.parse()
+	if err != nil {
+		return 0, err
+	}
 
-	alpha := carbon.Parse(dt.Start)
-	if alpha.Error != nil {
-		// fmt.Println("alpha:", alpha.Error)
-		start, err = now.Parse(dt.Start)
-		if err != nil {
-			return 0, err
-		}
-	} else {
-		start = alpha.StdTime()
+	dt.startTime, dt.endTime = start, end
+	dt.Diff = end.Sub(start)
+	return dt.Diff, nil
+}
+
+// overflowError return an ErrRangeOverflow when dt.Diff can not hold the difference from start to end,
+// which is limited to about 292 years; this is only valid after calling dur
+func (dt *DtDiff) overflowError() error {
+	if dt.startTime.Add(dt.Diff).Equal(dt.endTime) {
+		return nil
 	}
+	message := fmt.Sprintf("[DtDiff] Range overflow: the difference from %s to %s is more than about 292 years; use the calendar diff instead", dt.Start, dt.End)
+	return &InputError{Kind: ErrRangeOverflow, Input: dt.End, Field: FieldEnd, Position: -1, message: message}
+}
 
-	omega := carbon.Parse(dt.End)
-	if omega.Error != nil {
-		// fmt.Println("omega:", omega.Error)
-		end, err = now.Parse(dt.End)
-		if err != nil {
-			return 0, err
-		}
-	} else {
-		end = omega.StdTime()
+// parse return the start and end times after converting any relative dates
+func (dt *DtDiff) parse() (time.Time, time.Time, error) {
+	// both relative dates are based on the same current time
+	current := dt.opts.now()
+	start, err := parseRelative(dt.Start, FieldStart, current, dt.opts, parseDateTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
 	}
 
-	dt.Diff = end.Sub(start)
-	return dt.Diff, nil
+	end, err := parseRelative(dt.End, FieldEnd, current, dt.opts, parseDateTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// parseDateTime first try to parse with carbon, fallback to parsing with now if carbon fails to parse
+// values without an explicit offset or zone are interpreted in the location of "current"
+func parseDateTime(value string, current time.Time) (time.Time, error) {
+	alpha := carbon.NewCarbon().SetLocation(current.Location()).Parse(value)
+	if alpha.Error != nil {
+		return parseInLocation(value, current)
+	}
+	return alpha.StdTime(), nil
+}
+
+// parseInLocation parse with now, which uses the date of "current" for time-only values
+// and interprets values without an explicit offset or zone in the location of "current"
+func parseInLocation(value string, current time.Time) (time.Time, error) {
+	return now.With(current).Parse(value)
 }
 
 // format return a nicely formatted string version of dt.Diff
-func (dt *DtDiff) format() string {
+// or the calendar breakdown between start and end when that is enabled
+// a nil locale is English, which is also the basis of the brief format
+func (dt *DtDiff) format(locale *Locale) string {
+	// durafmt only writes a minus sign in front of the first unit
+	if locale != nil || dt.Precision > 0 || dt.useCalendarDiff() || dt.Diff < 0 {
+		return locale.formatParts(dt.parts(false))
+	}
 	format := durafmt.Parse(dt.Diff)
 	return fmt.Sprintf("%v", format)
 }
 
+// parts return the difference from start to end limited to dt.Precision units: the calendar breakdown when that
+// is enabled, otherwise the units of durafmt, or 24 hour days and clock units when "iso" is true
+func (dt *DtDiff) parts(iso bool) ([]part, bool) {
+	split, fixed := splitFunc(fixedParts), true
+	switch {
+	case dt.useCalendarDiff():
+		split, fixed = calendarBreakdown, false
+	case iso:
+		split = dayParts
+	}
+	return limitParts(dt.startTime, dt.endTime, dt.Precision, dt.Truncate, split, fixed)
+}
+
+// useCalendarDiff return the value given to SetCalendarDiff, otherwise
+// true when both start and end are dates without a time of day
+func (dt *DtDiff) useCalendarDiff() bool {
+	if dt.opts.calendarDiff != nil {
+		return *dt.opts.calendarDiff
+	}
+	return isDateOnly(dt.Start) && isDateOnly(dt.End)
+}
+
 // DtDiff a combination of both the dur and format functions
 // this is what is usually called by any consumers
 func (dt *DtDiff) DtDiff() (string, time.Duration, error) {
@@ -108,41 +210,78 @@ func (dt *DtDiff) DtDiff() (string, time.Duration, error) {
 		return "", 0, err
 	}
 
-	format := dt.format()
+	if dt.Precision < 0 {
+		return "", 0, fmt.Errorf("[DtDiff] Invalid precision: %d", dt.Precision)
+	}
+	if len(dt.opts.units) > 0 {
+		if unit := unitsOverflow(dt.startTime, dt.endTime, dt.opts.units); len(unit) > 0 {
+			message := fmt.Sprintf("[DtDiff] Range overflow: the difference from %s to %s is too large to count in %ss; allow a larger unit", dt.Start, dt.End, unit)
+			return "", 0, &InputError{Kind: ErrRangeOverflow, Input: dt.End, Field: FieldEnd, Position: -1, message: message}
+		}
+		split := func(start, end time.Time) ([]part, bool) {
+			return unitBreakdown(start, end, dt.opts.units)
+		}
+		parts, negative := limitParts(dt.startTime, dt.endTime, dt.Precision, dt.Truncate, split, false)
+		if dt.ISO8601 {
+			return formatISO8601(parts, negative), duration, nil
+		}
+		if isZero(parts) {
+			// the parts are every allowed unit, so a zero difference is given in the smallest, such as "0 hours"
+			unit := parts[len(parts)-1].unit
+			if dt.Brief {
+				return shrinkPeriod(formatZero(unit)), duration, nil
+			}
+			return dt.opts.locale.formatZero(unit), duration, nil
+		}
+		if dt.Brief {
+			return shrinkPeriod(formatParts(parts, negative)), duration, nil
+		}
+		return dt.opts.locale.formatParts(parts, negative), duration, nil
+	}
+	if !dt.useCalendarDiff() {
+		if err := dt.overflowError(); err != nil {
+			return "", 0, err
+		}
+	}
+	if dt.ISO8601 {
+		return formatISO8601(dt.parts(true)), duration, nil
+	}
 	if dt.Brief {
-		format = shrinkPeriod(format)
+		return shrinkPeriod(dt.format(nil)), duration, nil
 	}
-	return format, duration, nil
+	return dt.format(dt.opts.locale), duration, nil
 }
 
-// validatePeriod ensure all words in "period" are a valid time duration
-func validatePeriod(period string) error {
-	wordsOnlyRe := regexp.MustCompile(wordsOnly)
-	matches := wordsOnlyRe.FindAllString(period, -1)
-	for _, word := range matches {
-		// fmt.Println("word:", word)
-		_, ok := carbonFuncs[removeTrailingS(word)]
-		if !ok {
-			return fmt.Errorf("[validatePeriod] Invalid period: %s", word)
-		}
+// BusinessDays return the number of business days from start to end, skipping weekends
+// and the holidays of the calendar given to SetCalendar; negative when end is before start
+func (dt *DtDiff) BusinessDays() (int, error) {
+	start, end, err := dt.parse()
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	dt.startTime, dt.endTime = start, end
+	cal := dt.opts.businessCalendar()
+	if !hasBusinessDay(cal.Weekend) {
+		return 0, weekendError("BusinessDays", cal.Weekend)
+	}
+	return countBusinessDays(start, end.In(start.Location()), cal), nil
 }
 
-// convertRelativeDateToActual converts "yesterday", "today", "tomorrow"
-// into actual dates
-func convertRelativeDateToActual(from string) string {
-	switch strings.ToLower(from) {
-	case "now":
-		return carbon.Now().String()
-	case "today":
-		return carbon.Now().String()
-	case "yesterday":
-		return carbon.Yesterday().String()
-	case "tomorrow":
-		return carbon.Tomorrow().String()
+// parseRelative return the time of a relative phrase such as "yesterday" or "next monday",
+// based on "current"; any other value is given to "parse" along with "current" in the parse location
+// a failure is an ErrUnparseableDate for "field", such as FieldStart
+func parseRelative(value, field string, current time.Time, o options, parse func(string, time.Time) (time.Time, error)) (time.Time, error) {
+	t, ok, err := convertRelativeDate(value, current, o)
+	if !ok {
+		if t, ok := o.parseLayouts(value); ok {
+			return t, nil
+		}
+		t, err = parse(value, current)
+	}
+	if err != nil {
+		return t, dateError(value, field, err)
 	}
-	return from
+	return t, nil
 }
 
 // removeTrailingS convert plural to singular, such as "hours" to "hour"
@@ -155,99 +294,141 @@ func removeTrailingS(s string) string {
 
 // calculate Add or Sub a duration of time "period" from the "from" variable
 // index==0 then Add; index==1 then Sub
-func calculate(from, period string, index int) (string, error) {
-	periodMatches := expandedRegexp.FindAllStringSubmatch(period, -1)
-	if len(periodMatches) == 0 {
-		// brief format is being used so first expand it to the long format
-		period, err := expandPeriod(period)
-		if nil != err {
-			return "", fmt.Errorf("%v", err)
-		}
-		periodMatches = expandedRegexp.FindAllStringSubmatch(period, -1)
-		if len(periodMatches) == 0 {
-			return "", fmt.Errorf("[validatePeriod] Invalid duration: %s", period)
-		}
+func calculate(from, period string, index int, opts ...Option) (string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	parts, err := parsePeriod(period, o.repeatedUnits)
+	if err != nil {
+		return "", err
+	}
+	return calculateParts(from, period, parts, index, o)
+}
+
+// calculateParts Add or Sub the parts of "period" from the "from" variable; "period" is only used in error messages
+// index==0 then Add; index==1 then Sub
+func calculateParts(from, period string, parts []part, index int, o options) (string, error) {
+	f, err := parseFrom(from, FieldFrom, o)
+	if err != nil {
+		return "", err
 	}
 
-	from = convertRelativeDateToActual(from)
-	f, err := now.Parse(from)
+	to, err := step(f.StdTime(), period, parts, index, o)
 	if err != nil {
 		return "", err
 	}
+	return o.format(carbon.CreateFromStdTime(to)), nil
+}
+
+// Parse return "value" as a time, using the same rules as the "from" value of Add and Sub
+// relative dates such as "tomorrow" are converted and values without an offset are
+// interpreted in the parse location; the result is in the output location when one was given
+func Parse(value string, opts ...Option) (time.Time, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := parseFrom(value, "", o)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return o.outputTime(t.StdTime()), nil
+}
+
+// parseFrom convert relative dates or parse "from" in the parse location
+// "field" names the value in errors, such as FieldFrom or FieldUntil
+func parseFrom(from, field string, o options) (carbon.Carbon, error) {
+	f, err := parseRelative(from, field, o.now(), o, parseInLocation)
+	if err != nil {
+		return carbon.Carbon{}, err
+	}
 
 	to := carbon.CreateFromStdTime(f)
 	if to.Error != nil {
-		return "", to.Error
+		return carbon.Carbon{}, to.Error
 	}
-	err = validatePeriod(period)
+	return to, nil
+}
+
+// applyPeriod Add or Sub each duration found in "period" to "to"
+// each duration may carry its own sign, such as "1 month -2 days"
+// index==0 then Add; index==1 then Sub
+func applyPeriod(to carbon.Carbon, period string, index int, o options) (carbon.Carbon, error) {
+	parts, err := parsePeriod(period, o.repeatedUnits)
 	if err != nil {
-		return "", err
+		return to, err
 	}
+	return applyParts(to, period, parts, index, o)
+}
 
-	for i := range periodMatches {
-		amount := periodMatches[i][1]
-		num, err := strconv.Atoi(amount)
-		if err != nil {
-			return "", err
-		}
-		word := periodMatches[i][2]
-		// to understand this line of code, read: ChatGPT_Explanation.md
-		to = carbonFuncs[removeTrailingS(word)].([2]interface{})[index].(func(carbon.Carbon, int) carbon.Carbon)(to, num)
-		// fmt.Printf("    to: %v | %v | %v\n", num, word, to)
+// maxYears is the largest amount of any unit, measured in years, and the largest year of a result;
+// both are far inside the range of time.Time, so that an overflow is reported instead of wrapping around
+const maxYears = 1_000_000_000
+
+// amountOverflows return true when "num" of "unit" can not be added without overflowing
+// fixed length units must fit in a time.Duration and the others in maxYears
+func amountOverflows(unit string, num int64) bool {
+	switch unit {
+	case "year":
+		return num > maxYears
+	case "month":
+		return num > maxYears*12
+	case "week":
+		return num > maxYears*52
+	case "day", businessDay:
+		return num > maxYears*365
 	}
-	return to.ToString(), nil
+	return num > math.MaxInt64/int64(fixedUnits[unit])
 }
 
-// expandPeriod convert a brief style period into a long period
-// only allow one replacement per each period
-// Ex: 1h2m3s => 1 hour 2 minutes 3 seconds
-func expandPeriod(period string) (string, error) {
-	// a direct string replace will not work because some
-	// periods have overlapping strings, such as 's' with 'ms, 'us', 'ns'
-	// therefore convert each period to a unique string first
-	s := period
-	s = strings.Replace(s, "ns", "α", 1)
-	s = strings.Replace(s, "us", "β", 1)
-	s = strings.Replace(s, "µs", "β", 1)
-	s = strings.Replace(s, "ms", "γ", 1)
-	s = strings.Replace(s, "s", "δ", 1)
-	s = strings.Replace(s, "m", "ε", 1)
-	s = strings.Replace(s, "h", "ζ", 1)
-	s = strings.Replace(s, "D", "η", 1)
-	s = strings.Replace(s, "W", "θ", 1)
-	s = strings.Replace(s, "M", "ι", 1)
-	s = strings.Replace(s, "Y", "λ", 1)
-
-	// now convert from the unique string back to the corresponding duration
-	p := s
-	p = strings.Replace(p, "α", " nanoseconds ", 1)
-	p = strings.Replace(p, "β", " microseconds ", 1)
-	p = strings.Replace(p, "γ", " milliseconds ", 1)
-	p = strings.Replace(p, "δ", " seconds ", 1)
-	p = strings.Replace(p, "ε", " minutes ", 1)
-	p = strings.Replace(p, "ζ", " hours ", 1)
-	p = strings.Replace(p, "η", " days ", 1)
-	p = strings.Replace(p, "θ", " weeks ", 1)
-	p = strings.Replace(p, "ι", " months ", 1)
-	p = strings.Replace(p, "λ", " years ", 1)
-
-	// ensure each time & period was successfully replaced
-	// len of Fields should always be even because is part
-	// of the period is a two element tuple of
-	// a numeric amount and a duration
-	words := strings.Fields(p)
-	if len(words)%2 == 1 {
-		return "", fmt.Errorf("[expandPeriod] Invalid period: %s. %s", period, dupMsg)
-	}
-
-	// check that every other element is a number
-	for i := 0; i < len(words); i += 2 {
-		_, err := strconv.Atoi(words[i])
-		if err != nil {
-			return "", fmt.Errorf("[expandPeriod] %v. %s", err, dupMsg)
+// applyParts Add or Sub each of the parts of "period" to "to"
+// index==0 then Add; index==1 then Sub
+// an amount or a result which is too large is an ErrRangeOverflow for "period", as the caller gave it
+func applyParts(to carbon.Carbon, period string, parts []part, index int, o options) (carbon.Carbon, error) {
+	var err error
+	for _, p := range parts {
+		num := int(p.amount)
+		fraction := p.fraction
+		// a negative amount goes in the opposite direction of the operation
+		direction := index
+		if num < 0 || (num == 0 && fraction < 0) {
+			num, fraction = -num, -fraction
+			direction = 1 - index
+		}
+		if num < 0 || amountOverflows(p.unit, int64(num)) {
+			return to, overflowPeriodError(period, parts, fmt.Sprintf("the amount %s is too large", formatParts([]part{p}, false)))
+		}
+		if p.unit == businessDay {
+			to, err = addBusinessDays(to, num, direction, o)
+			if err != nil {
+				return to, err
+			}
+		} else {
+			// to understand this line of code, read: ChatGPT_Explanation.md
+			to = carbonFuncs[p.unit].([2]interface{})[direction].(func(carbon.Carbon, int) carbon.Carbon)(to, num)
+		}
+		if fraction != 0 {
+			to, err = applyFraction(to, p.unit, fraction, direction, o)
+			if err != nil {
+				return to, err
+			}
+		}
+		if year := to.StdTime().Year(); year > maxYears || year < -maxYears {
+			return to, overflowPeriodError(period, parts, fmt.Sprintf("the result is past the year %d", maxYears))
 		}
 	}
-	return p, nil
+	return to, nil
+}
+
+// overflowPeriodError return an ErrRangeOverflow for "period", or for the period made of "parts"
+// when the period was not given as text, such as one computed by Eval
+func overflowPeriodError(period string, parts []part, detail string) error {
+	if len(period) == 0 {
+		period = formatParts(parts, false)
+	}
+	message := fmt.Sprintf("[applyParts] Range overflow in period %q: %s", period, detail)
+	return periodError(ErrRangeOverflow, period, -1, message)
 }
 
 // shrinkPeriod convert a period into a brief period
@@ -283,93 +464,115 @@ func shrinkPeriod(period string) string {
 
 // Add adds the "period" duration to "from"
 // this is what is usually called by any consumers
-func Add(from, period string) (string, error) {
-	return calculate(from, period, 0)
+func Add(from, period string, opts ...Option) (string, error) {
+	return calculate(from, period, 0, opts...)
 }
 
 // Sub subtracts the "period" duration from "from"
 // this is what is usually called by any consumers
-func Sub(from, period string) (string, error) {
-	return calculate(from, period, 1)
+func Sub(from, period string, opts ...Option) (string, error) {
+	return calculate(from, period, 1, opts...)
 }
 
 // calculateWithRecurrence similar to calculate, but returns
 // a slice of multiple past or future date/times at intervals of length 'period'
 // index==0 then Add; index==1 then Sub
-func calculateWithRecurrence(from, period string, index, recurrence int) ([]string, error) {
-	var all []string
-	var err error
-	for i := 0; i < recurrence; i++ {
-		from, err = calculate(from, period, index)
-		if err != nil {
-			return nil, err
-		}
-		all = append(all, from)
+func calculateWithRecurrence(from, period string, index, recurrence int, opts ...Option) ([]string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
 	}
-	return all, nil
+	parts, err := parsePeriod(period, o.repeatedUnits)
+	if err != nil {
+		return nil, err
+	}
+	return calculatePartsWithRecurrence(from, period, parts, index, recurrence, o)
+}
+
+// calculatePartsWithRecurrence similar to calculateParts, but returns
+// a slice of multiple past or future date/times at intervals of length 'parts'; "period" is only used in error messages
+// index==0 then Add; index==1 then Sub
+func calculatePartsWithRecurrence(from, period string, parts []part, index, recurrence int, o options) ([]string, error) {
+	f, err := parseFrom(from, FieldFrom, o)
+	if err != nil {
+		return nil, err
+	}
+	all, err := stepWithRecurrence(f.StdTime(), period, parts, index, recurrence, o)
+	if err != nil {
+		return nil, err
+	}
+	return formatTimes(all, o), nil
 }
 
 // AddWithRecurrence similar to Add, but returns a slice
 // of multiple future dates/times at intervals of length 'period'
-func AddWithRecurrence(from, period string, recurrence int) ([]string, error) {
-	return calculateWithRecurrence(from, period, 0, recurrence)
+func AddWithRecurrence(from, period string, recurrence int, opts ...Option) ([]string, error) {
+	return calculateWithRecurrence(from, period, 0, recurrence, opts...)
 }
 
 // SubWithRecurrence similar to Sub, but returns a slice
 // of multiple past dates/times at intervals of length 'period'
-func SubWithRecurrence(from, period string, recurrence int) ([]string, error) {
-	return calculateWithRecurrence(from, period, 1, recurrence)
+func SubWithRecurrence(from, period string, recurrence int, opts ...Option) ([]string, error) {
+	return calculateWithRecurrence(from, period, 1, recurrence, opts...)
 }
 
 // calculateUntil similar to calculate, but returns
 // a slice of multiple past or future date/times at intervals until
 // the 'until' date/time is exceeded
 // index==0 then Add; index==1 then Sub
-func calculateUntil(from, until, period string, index int) ([]string, error) {
-	var all []string
-	var f, u time.Time
-	var err error
-
-	until = convertRelativeDateToActual(until)
-	u, err = now.Parse(until)
+func calculateUntil(from, until, period string, index int, opts ...Option) ([]string, error) {
+	o, err := newOptions(opts)
 	if err != nil {
 		return nil, err
 	}
+	parts, err := parsePeriod(period, o.repeatedUnits)
+	if err != nil {
+		return nil, err
+	}
+	return calculatePartsUntil(from, until, period, parts, index, o)
+}
 
-	from = convertRelativeDateToActual(from)
-	for {
-		from, err = calculate(from, period, index)
-		if err != nil {
-			return nil, err
-		}
+// calculatePartsUntil similar to calculateParts, but returns
+// a slice of multiple past or future date/times at intervals until
+// the 'until' date/time is exceeded; "period" is only used in error messages
+// index==0 then Add; index==1 then Sub
+func calculatePartsUntil(from, until, period string, parts []part, index int, o options) ([]string, error) {
+	u, err := parseFrom(until, FieldUntil, o)
+	if err != nil {
+		return nil, err
+	}
 
-		f, err = now.Parse(from)
-		if err != nil {
-			return nil, err
-		}
+	f, err := parseFrom(from, FieldFrom, o)
+	if err != nil {
+		return nil, err
+	}
+	it := (&TimeIterator{t: f.StdTime(), parts: parts, index: index, o: o, period: period}).Until(u.StdTime())
+	// report "until" as it was given
+	it.untilName = until
+	all, err := it.all()
+	if err != nil {
+		return nil, err
+	}
+	return formatTimes(all, o), nil
+}
 
-		if index == 0 {
-			if f.After(u) {
-				break
-			}
-		} else {
-			if f.Before(u) {
-				break
-			}
-		}
-		all = append(all, from)
+// movesToward return true when "next" is after "previous" for Add (index==0)
+// or before "previous" for Sub (index==1)
+func movesToward(previous, next time.Time, index int) bool {
+	if index == 0 {
+		return next.After(previous)
 	}
-	return all, nil
+	return next.Before(previous)
 }
 
 // AddUntil similar to Add, but returns a slice
 // of multiple future dates/times until date/time exceed 'until'
-func AddUntil(from, until, period string) ([]string, error) {
-	return calculateUntil(from, until, period, 0)
+func AddUntil(from, until, period string, opts ...Option) ([]string, error) {
+	return calculateUntil(from, until, period, 0, opts...)
 }
 
 // SubUntil similar to Sub, but returns a slice
 // of multiple past dates/times until date/time exceed 'until'
-func SubUntil(from, until, period string) ([]string, error) {
-	return calculateUntil(from, until, period, 1)
+func SubUntil(from, until, period string, opts ...Option) ([]string, error) {
+	return calculateUntil(from, until, period, 1, opts...)
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/golang-module/carbon/v2"
 	"strings"
 	"testing"
+	"time"
 )
 
 func testStartEnd(t *testing.T, start, end, correct string) {
@@ -280,3 +281,127 @@ func TestRelativeUntil(t *testing.T) {
 	allCorrectAdd[2] = fmt.Sprintf("%s", strings.Replace(from, "00:00:00", "23:57:03", 1))
 	testAddUntil(t, from, until, period, allCorrectAdd)
 }
+
+func TestWithLocation(t *testing.T) {
+	berlin, err := LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// crosses the daylight saving time change on 2024-03-31
+	future, err := Add("2024-03-30 12:00:00", "1 day", WithLocation(berlin))
+	if err != nil {
+		t.Error(err)
+	}
+	correct := "2024-03-31 12:00:00 +0200 CEST"
+	if future != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", future, correct)
+	}
+
+	utc, _ := LoadLocation("UTC")
+	future, err = Add("2024-03-30 12:00:00", "1 day", WithParseLocation(berlin), WithOutputLocation(utc))
+	if err != nil {
+		t.Error(err)
+	}
+	correct = "2024-03-31 10:00:00 +0000 UTC"
+	if future != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", future, correct)
+	}
+
+	dt := New("2024-03-31 00:00:00", "2024-03-31 05:00:00")
+	dt.SetLocation(berlin, nil)
+	format, _, err := dt.DtDiff()
+	if err != nil {
+		t.Error(err)
+	}
+	if format != "4 hours" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "4 hours")
+	}
+}
+
+func TestParse(t *testing.T) {
+	utc, _ := LoadLocation("UTC")
+	plus2, _ := LoadLocation("+02:00")
+	parsed, err := Parse("2024-06-07 08:00:00", WithParseLocation(plus2), WithOutputLocation(utc))
+	if err != nil {
+		t.Error(err)
+	}
+	correct := "2024-06-07T06:00:00Z"
+	if parsed.Format(time.RFC3339) != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", parsed.Format(time.RFC3339), correct)
+	}
+
+	dt := New("2024-06-07T08:00:00+02:00", "2024-06-07T09:00:00+02:00")
+	dt.SetLocation(nil, utc)
+	if _, _, err = dt.DtDiff(); err != nil {
+		t.Error(err)
+	}
+	if dt.StartTime().Format(time.RFC3339) != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", dt.StartTime().Format(time.RFC3339), correct)
+	}
+	if dt.EndTime().Format(time.RFC3339) != "2024-06-07T07:00:00Z" {
+		t.Errorf("[computed: %v] != [correct: %v]", dt.EndTime().Format(time.RFC3339), "2024-06-07T07:00:00Z")
+	}
+}
+
+func TestSignedPeriods(t *testing.T) {
+	from := "2024-03-15T10:00:00Z"
+	testAddSubContains(t, from, "1 month -2 days", "2024-04-13 10:00:00", "2024-02-17 10:00:00")
+	testAddSubContains(t, from, "1M-2D", "2024-04-13 10:00:00", "2024-02-17 10:00:00")
+	testAddSubContains(t, from, "-3 days", "2024-03-12 10:00:00", "2024-03-18 10:00:00")
+	testAddSubContains(t, from, "+2h-30m", "2024-03-15 11:30:00", "2024-03-15 08:30:00")
+	testAddSubContains(t, from, "-2BD", "2024-03-13 10:00:00", "2024-03-19 10:00:00")
+
+	allCorrectAdd := []string{"2024-03-20 10:00:00", "2024-03-25 10:00:00"}
+	allCorrectSub := []string{"2024-03-10 10:00:00", "2024-03-05 10:00:00"}
+	testAddSubWithRecurrence(t, from, "1W-2D", allCorrectAdd, allCorrectSub, 2)
+	testAddUntil(t, from, "2024-03-26T00:00:00Z", "1W-2D", allCorrectAdd)
+}
+
+func TestUntilWithoutProgress(t *testing.T) {
+	from := "2024-03-15T10:00:00Z"
+	_, err := AddUntil(from, "2024-04-01", "1 day -24 hours")
+	if err == nil {
+		t.Errorf("AddUntil should fail when the period does not move toward until")
+	}
+	_, err = SubUntil(from, "2024-01-01", "1 month -40 days")
+	if err == nil {
+		t.Errorf("SubUntil should fail when the period moves away from until")
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	utc, _ := LoadLocation("UTC")
+	clock := FixedClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	dt, err := NewWithOptions("2024-04-30T11:00:00Z", "now", WithBrief(), WithClock(clock), WithLocation(utc))
+	if err != nil {
+		t.Error(err)
+	}
+	format, _, err := dt.DtDiff()
+	if err != nil {
+		t.Error(err)
+	}
+	if format != "1D1h" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "1D1h")
+	}
+
+	dt, _ = NewWithOptions("2024-01-31 00:00:00", "2024-03-03 00:00:00", WithCalendarDiff(true), WithISO8601())
+	format, _, err = dt.DtDiff()
+	if err != nil {
+		t.Error(err)
+	}
+	if format != "P1M1D" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "P1M1D")
+	}
+
+	// SetBrief still works after NewWithOptions
+	dt, _ = NewWithOptions("2024-01-31", "2024-03-03")
+	dt.SetBrief(true)
+	format, _, _ = dt.DtDiff()
+	if format != "1M1D" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "1M1D")
+	}
+
+	if _, err := NewWithOptions("2024-01-31", "2024-03-03", WithStrftime("%Q")); err == nil {
+		t.Errorf("NewWithOptions should return the error of an invalid option")
+	}
+}
@@ -0,0 +1,111 @@
+package dtdiff
+
+import (
+	"errors"
+	"fmt"
+)
+
+// these sentinels can be used with errors.Is to tell apart the kinds of invalid input,
+// use errors.As with *InputError to find the offending input, field and position
+var (
+	// ErrInvalidPeriod a period can not be parsed or used, such as "1h2x"
+	ErrInvalidPeriod = errors.New("invalid period")
+	// ErrUnparseableDate a date, time or relative date can not be parsed, such as "next blah"
+	ErrUnparseableDate = errors.New("unparseable date")
+	// ErrDuplicateUnit a unit is given more than once in a period, such as "1m30s5m"; this is also an ErrInvalidPeriod
+	ErrDuplicateUnit = errors.New("duplicate unit")
+	// ErrRangeOverflow an amount or a difference is too large to be represented
+	ErrRangeOverflow = errors.New("range overflow")
+	// ErrInvalidCalendar a weekend or holiday can not be used, such as a weekend of every day of the week
+	ErrInvalidCalendar = errors.New("invalid calendar")
+	// ErrInvalidExpression an expression given to Eval can not be evaluated, such as "2024-01-01 * 2"
+	ErrInvalidExpression = errors.New("invalid expression")
+	// ErrInvalidCron a cron expression can not be parsed, such as "61 * * * *", or never matches, such as "0 0 30 2 *"
+	ErrInvalidCron = errors.New("invalid cron expression")
+)
+
+// the fields reported by InputError
+const (
+	FieldStart  string = "start"
+	FieldEnd    string = "end"
+	FieldFrom   string = "from"
+	FieldUntil  string = "until"
+	FieldPeriod string = "period"
+	FieldCron   string = "cron"
+	// the weekend or holidays of a Calendar
+	FieldCalendar string = "calendar"
+	// an expression given to Eval
+	FieldExpression string = "expression"
+)
+
+// InputError is returned for invalid input
+type InputError struct {
+	// Kind is one of the sentinels above, such as ErrInvalidPeriod or ErrUnparseableDate
+	Kind error
+	// Input is the offending value, such as the whole period or date
+	Input string
+	// Field is one of the fields above, such as FieldStart or FieldPeriod
+	Field string
+	// Position is the character offset of the problem within Input, or -1 when it does not apply
+	Position int
+	// Err is the underlying error, if any
+	Err error
+	// message is returned by Error, such as: [ParsePeriod] Invalid period "1h2x" at offset 3: unknown unit "x"
+	message string
+}
+
+// Error return the message of the error
+func (e *InputError) Error() string {
+	return e.message
+}
+
+// Is return true for the Kind of the error; a duplicate unit or an amount
+// out of range within a period is also an ErrInvalidPeriod
+func (e *InputError) Is(target error) bool {
+	return target == e.Kind || (target == ErrInvalidPeriod && e.Field == FieldPeriod)
+}
+
+// Unwrap return the underlying error
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// periodError return an InputError of "kind" for the period "input"
+func periodError(kind error, input string, position int, message string) *InputError {
+	return &InputError{Kind: kind, Input: input, Field: FieldPeriod, Position: position, message: message}
+}
+
+// calendarError return an ErrInvalidCalendar for "input", such as a list of weekend days
+func calendarError(input, message string) *InputError {
+	return &InputError{Kind: ErrInvalidCalendar, Input: input, Field: FieldCalendar, Position: -1, message: message}
+}
+
+// expressionError return an InputError of "kind" for the Eval "expression", where "position"
+// is the offset of the offending token, caused by err when it is not nil
+func expressionError(kind error, expression string, position int, err error, message string) *InputError {
+	return &InputError{Kind: kind, Input: expression, Field: FieldExpression, Position: position, Err: err, message: message}
+}
+
+// cronError return an ErrInvalidCron for the cron "expression" found by "caller", such as "ParseCron"
+// "position" is the offset of the offending field, or -1 when the problem is not in one field
+func cronError(caller, expression string, position int, detail string) *InputError {
+	message := fmt.Sprintf("[%s] Invalid cron expression %q: %s", caller, expression, detail)
+	if position >= 0 {
+		message = fmt.Sprintf("[%s] Invalid cron expression %q at offset %d: %s", caller, expression, position, detail)
+	}
+	return &InputError{Kind: ErrInvalidCron, Input: expression, Field: FieldCron, Position: position, message: message}
+}
+
+// dateError return an ErrUnparseableDate for the date "input" of "field", caused by err
+func dateError(input, field string, err error) error {
+	var inputErr *InputError
+	if errors.As(err, &inputErr) {
+		return err
+	}
+	name := "date"
+	if len(field) > 0 {
+		name = field + " date"
+	}
+	message := fmt.Sprintf("[parseRelative] Unparseable %s %q: %v", name, input, err)
+	return &InputError{Kind: ErrUnparseableDate, Input: input, Field: field, Position: -1, Err: err, message: message}
+}
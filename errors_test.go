@@ -0,0 +1,75 @@
+package dtdiff
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInputError(t *testing.T) {
+	tests := []struct {
+		err      error
+		kind     error
+		field    string
+		input    string
+		position int
+	}{
+		{ValidatePeriod("1h2x"), ErrInvalidPeriod, FieldPeriod, "1h2x", 3},
+		{ValidatePeriod("1m30s5m"), ErrDuplicateUnit, FieldPeriod, "1m30s5m", 6},
+		{ValidatePeriod("P1D1D"), ErrDuplicateUnit, FieldPeriod, "P1D1D", 4},
+		{ValidatePeriod("99999999999999999999h"), ErrRangeOverflow, FieldPeriod, "99999999999999999999h", 0},
+		{second(Add("next blah", "1D")), ErrUnparseableDate, FieldFrom, "next blah", -1},
+		{second(AddUntil("2024-01-01", "bogus", "1D")), ErrUnparseableDate, FieldUntil, "bogus", -1},
+		{third(New("2024-01-01", "bogus").DtDiff()), ErrUnparseableDate, FieldEnd, "bogus", -1},
+		{third(New("1600-01-01T00:00:00Z", "2024-01-01T00:00:00Z").DtDiff()), ErrRangeOverflow, FieldEnd, "2024-01-01T00:00:00Z", -1},
+		{second(AddUntil("2024-01-01", "2024-02-01", "1D-24h")), ErrInvalidPeriod, FieldPeriod, "1D-24h", -1},
+		{second(Add("2024-01-01", "9999999999999999h")), ErrRangeOverflow, FieldPeriod, "9999999999999999h", -1},
+		{second(Add("2024-01-01", "999999999999Y")), ErrRangeOverflow, FieldPeriod, "999999999999Y", -1},
+		{second(Sub("2024-01-01", "1M-99999999999999D")), ErrRangeOverflow, FieldPeriod, "1M-99999999999999D", -1},
+		{second(AddWithRecurrence("2024-01-01", "500000000Y", 3)), ErrRangeOverflow, FieldPeriod, "500000000Y", -1},
+		{second(AddTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Period{Years: 2e9})), ErrRangeOverflow, FieldPeriod, "2000000000 years", -1},
+		{second(Eval("2024-01-01 + 999999999999Y")), ErrRangeOverflow, FieldPeriod, "999999999999Y", -1},
+		{second(Eval("2024-01-01 + 999999999999Y * 2")), ErrRangeOverflow, FieldPeriod, "1999999999998 years", -1},
+		{second(Eval("2024-01-01 + bogus")), ErrUnparseableDate, FieldExpression, "2024-01-01 + bogus", 13},
+		{second(Eval("2024-01-01 * 2")), ErrInvalidExpression, FieldExpression, "2024-01-01 * 2", 11},
+		{second(Eval("(2024-01-01 + 1D")), ErrInvalidExpression, FieldExpression, "(2024-01-01 + 1D", 0},
+		{second(ParseWeekend("sat,funday")), ErrInvalidCalendar, FieldCalendar, "sat,funday", -1},
+		{second(ParseWeekend("mon,tue,wed,thu,fri,sat,sun")), ErrInvalidCalendar, FieldCalendar, "mon,tue,wed,thu,fri,sat,sun", -1},
+		{second(Add("2024-01-01", "1BD", WithCalendar(NewCalendar(time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday)))), ErrInvalidCalendar, FieldCalendar, "sun,mon,tue,wed,thu,fri,sat", -1},
+	}
+	for i, test := range tests {
+		if !errors.Is(test.err, test.kind) {
+			t.Errorf("[test: %d] [computed: %v] is not: [correct: %v]", i, test.err, test.kind)
+			continue
+		}
+		var inputErr *InputError
+		if !errors.As(test.err, &inputErr) {
+			t.Errorf("[test: %d] [computed: %T] is not an *InputError", i, test.err)
+			continue
+		}
+		if inputErr.Field != test.field || inputErr.Position != test.position {
+			t.Errorf("[test: %d] [computed: %v %v] != [correct: %v %v]", i, inputErr.Field, inputErr.Position, test.field, test.position)
+		}
+		if len(test.input) > 0 && inputErr.Input != test.input {
+			t.Errorf("[test: %d] [computed: %v] != [correct: %v]", i, inputErr.Input, test.input)
+		}
+	}
+
+	// a duplicate unit is also an invalid period, but a bad date is not
+	if err := ValidatePeriod("1D1D"); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("[computed: %v] is not: [correct: %v]", err, ErrInvalidPeriod)
+	}
+	if _, err := Add("bogus", "1D"); errors.Is(err, ErrInvalidPeriod) || errors.Is(err, ErrDuplicateUnit) {
+		t.Errorf("[computed: %v] should only be: [correct: %v]", err, ErrUnparseableDate)
+	}
+}
+
+// second return the error of a function returning two values
+func second(_ interface{}, err error) error {
+	return err
+}
+
+// third return the error of a function returning three values
+func third(_, _ interface{}, err error) error {
+	return err
+}
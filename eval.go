@@ -0,0 +1,433 @@
+package dtdiff
+
+import (
+	"fmt"
+	"github.com/golang-module/carbon/v2"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// kinds of values produced while evaluating an expression
+const (
+	valueTime = iota
+	valuePeriod
+	valueInteger
+)
+
+var integerRegexp = regexp.MustCompile(`^\d+$`)
+
+// unitOrder is the order used when periods are combined by + and -
+var unitOrder = []string{"year", "month", "week", "day", businessDay, "hour", "minute", "second", "millisecond", "microsecond", "nanosecond"}
+
+// evalToken is an operator, a parenthesis or a literal such as "2024-01-01" or "3D"
+type evalToken struct {
+	text   string
+	offset int
+	// operator, '(' or ')'; 0 for a literal
+	kind rune
+}
+
+// evalValue is the result of evaluating part of an expression
+type evalValue struct {
+	kind int
+	to   carbon.Carbon
+	// true when a time has no time of day, so differences follow the calendar
+	dateOnly bool
+	parts    []part
+	integer  int64
+	// the literal text, used to reinterpret an integer such as 20240101 as a date
+	text string
+}
+
+// evaluator is a recursive descent parser for:
+//
+//	expression = term { ("+" | "-") term }
+//	term       = factor { ("*" | "×") factor }
+//	factor     = "(" expression ")" | literal
+type evaluator struct {
+	input  string
+	tokens []evalToken
+	pos    int
+	o      options
+}
+
+// Eval evaluate a date arithmetic expression, such as "2024-01-01 + 3D - 2h" or "(2024-06-01 - 2024-01-01) * 2"
+// the supported operations are: datetime ± period, datetime - datetime, period ± period and period × integer
+// operators must be surrounded by spaces so that dates such as 2024-01-01 and signed periods such as 1M-2D
+// are read as a single value; the result is either a datetime, formatted the same way as Add,
+// or a period; datetime - datetime walks the calendar when both are dates without a time of day, the same as DtDiff,
+// and is otherwise given in weeks, days and clock units, such as "521 weeks 5 days", so that adding it back is exact
+func Eval(expression string, opts ...Option) (string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	tokens, err := tokenizeExpression(expression)
+	if err != nil {
+		return "", err
+	}
+	e := evaluator{input: expression, tokens: tokens, o: o}
+	v, err := e.expression()
+	if err != nil {
+		return "", err
+	}
+	if e.pos < len(e.tokens) {
+		t := e.tokens[e.pos]
+		return "", e.errorf(t.offset, "[Eval] Unexpected %q at offset %d", t.text, t.offset)
+	}
+
+	switch v.kind {
+	case valueTime:
+		return o.format(v.to), nil
+	case valuePeriod:
+		return o.locale.formatParts(v.parts, false), nil
+	}
+	return "", e.errorf(-1, "[Eval] Invalid expression: %s; the result is the integer %d", expression, v.integer)
+}
+
+// overflowError return an ErrRangeOverflow for an operator whose result does not fit in an int64
+func (e *evaluator) overflowError(op evalToken) error {
+	message := fmt.Sprintf("[Eval] Range overflow at offset %d: the result of %s is too large", op.offset, op.text)
+	return expressionError(ErrRangeOverflow, e.input, op.offset, nil, message)
+}
+
+// errorf return an ErrInvalidExpression for the token at "offset", or -1 for the whole expression
+func (e *evaluator) errorf(offset int, format string, args ...interface{}) error {
+	return expressionError(ErrInvalidExpression, e.input, offset, nil, fmt.Sprintf(format, args...))
+}
+
+// tokenizeExpression split an expression into literals, operators and parentheses
+// + and - are only operators when surrounded by spaces or parentheses
+func tokenizeExpression(expression string) ([]evalToken, error) {
+	var tokens []evalToken
+	runes := []rune(expression)
+	literalStart := -1
+	flush := func(end int) {
+		if literalStart < 0 {
+			return
+		}
+		text := strings.TrimSpace(string(runes[literalStart:end]))
+		if len(text) > 0 {
+			tokens = append(tokens, evalToken{text: text, offset: literalStart})
+		}
+		literalStart = -1
+	}
+	spaceOrParen := func(i int) bool {
+		return i < 0 || i >= len(runes) || unicode.IsSpace(runes[i]) || runes[i] == '(' || runes[i] == ')'
+	}
+	blank := func(end int) bool {
+		return literalStart < 0 || len(strings.TrimSpace(string(runes[literalStart:end]))) == 0
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '(' && blank(i):
+			flush(i)
+			tokens = append(tokens, evalToken{text: "(", offset: i, kind: '('})
+			continue
+		case r == ')':
+			flush(i)
+			tokens = append(tokens, evalToken{text: ")", offset: i, kind: ')'})
+			continue
+		case r == '*' || r == '×':
+			flush(i)
+			tokens = append(tokens, evalToken{text: string(r), offset: i, kind: '*'})
+			continue
+		case (r == '+' || r == '-') && spaceOrParen(i-1) && spaceOrParen(i+1):
+			flush(i)
+			tokens = append(tokens, evalToken{text: string(r), offset: i, kind: r})
+			continue
+		}
+		if literalStart < 0 && !unicode.IsSpace(r) {
+			literalStart = i
+		}
+	}
+	flush(len(runes))
+	if len(tokens) == 0 {
+		return nil, expressionError(ErrInvalidExpression, expression, -1, nil, "[Eval] Empty expression")
+	}
+	return tokens, nil
+}
+
+// peek return the kind of the current token, or -1 at the end of the expression
+func (e *evaluator) peek() rune {
+	if e.pos >= len(e.tokens) {
+		return -1
+	}
+	return e.tokens[e.pos].kind
+}
+
+// expression = term { ("+" | "-") term }
+func (e *evaluator) expression() (evalValue, error) {
+	left, err := e.term()
+	if err != nil {
+		return left, err
+	}
+	for e.peek() == '+' || e.peek() == '-' {
+		op := e.tokens[e.pos]
+		e.pos++
+		right, err := e.term()
+		if err != nil {
+			return right, err
+		}
+		if left, err = e.addSub(left, right, op); err != nil {
+			return left, err
+		}
+	}
+	return left, nil
+}
+
+// term = factor { ("*" | "×") factor }
+func (e *evaluator) term() (evalValue, error) {
+	left, err := e.factor()
+	if err != nil {
+		return left, err
+	}
+	for e.peek() == '*' {
+		op := e.tokens[e.pos]
+		e.pos++
+		right, err := e.factor()
+		if err != nil {
+			return right, err
+		}
+		if left, err = e.multiply(left, right, op); err != nil {
+			return left, err
+		}
+	}
+	return left, nil
+}
+
+// factor = "(" expression ")" | literal
+func (e *evaluator) factor() (evalValue, error) {
+	if e.pos >= len(e.tokens) {
+		return evalValue{}, e.errorf(len(e.input), "[Eval] Unexpected end of expression")
+	}
+	t := e.tokens[e.pos]
+	e.pos++
+	switch t.kind {
+	case '(':
+		v, err := e.expression()
+		if err != nil {
+			return v, err
+		}
+		if e.peek() != ')' {
+			return v, e.errorf(t.offset, "[Eval] Missing ) for ( at offset %d", t.offset)
+		}
+		e.pos++
+		return v, nil
+	case 0:
+		return e.literal(t)
+	}
+	return evalValue{}, e.errorf(t.offset, "[Eval] Unexpected %q at offset %d", t.text, t.offset)
+}
+
+// literal convert t into an integer, a period or a datetime, in that order
+func (e *evaluator) literal(t evalToken) (evalValue, error) {
+	if integerRegexp.MatchString(t.text) {
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err == nil {
+			return evalValue{kind: valueInteger, integer: n, text: t.text}, nil
+		}
+	}
+	if parts, err := parsePeriod(t.text, e.o.repeatedUnits); err == nil {
+		return evalValue{kind: valuePeriod, parts: parts, text: t.text}, nil
+	}
+	to, err := parseFrom(t.text, "", e.o)
+	if err != nil {
+		message := fmt.Sprintf("[Eval] Invalid date or period at offset %d: %s", t.offset, t.text)
+		return evalValue{}, expressionError(ErrUnparseableDate, e.input, t.offset, err, message)
+	}
+	return evalValue{kind: valueTime, to: to, dateOnly: isDateOnly(t.text), text: t.text}, nil
+}
+
+// asTime reinterpret an integer literal, such as 20240101, as a datetime
+func (e *evaluator) asTime(v evalValue) evalValue {
+	if v.kind != valueInteger {
+		return v
+	}
+	to, err := parseFrom(v.text, "", e.o)
+	if err != nil {
+		return v
+	}
+	return evalValue{kind: valueTime, to: to, dateOnly: isDateOnly(v.text), text: v.text}
+}
+
+// addSub evaluate left + right or left - right
+func (e *evaluator) addSub(left, right evalValue, op evalToken) (evalValue, error) {
+	left, right = e.asTime(left), e.asTime(right)
+	index := 0
+	if op.kind == '-' {
+		index = 1
+	}
+
+	switch {
+	case left.kind == valueTime && right.kind == valuePeriod:
+		return e.shift(left, right, index)
+	case left.kind == valuePeriod && right.kind == valueTime && index == 0:
+		return e.shift(right, left, index)
+	case left.kind == valuePeriod && right.kind == valuePeriod:
+		rightParts := right.parts
+		if index == 1 {
+			var ok bool
+			if rightParts, ok = scalePartsChecked(right.parts, -1); !ok {
+				return evalValue{}, e.overflowError(op)
+			}
+		}
+		if !sumFits(left.parts, rightParts) {
+			return evalValue{}, e.overflowError(op)
+		}
+		return evalValue{kind: valuePeriod, parts: combineParts(left.parts, rightParts)}, nil
+	case left.kind == valueTime && right.kind == valueTime && index == 1:
+		return evalValue{kind: valuePeriod, parts: e.difference(right, left)}, nil
+	}
+	return evalValue{}, e.errorf(op.offset, "[Eval] Invalid operation at offset %d: %s %s %s", op.offset, kindName(left), op.text, kindName(right))
+}
+
+// shift Add or Sub the period p to the datetime v; the result keeps no time of day only when p has no clock units
+func (e *evaluator) shift(v, p evalValue, index int) (evalValue, error) {
+	parts := p.parts
+	to, err := applyParts(v.to, p.text, parts, index, e.o)
+	if err != nil {
+		return v, err
+	}
+	dateOnly := v.dateOnly
+	for _, p := range parts {
+		if _, ok := fixedUnits[p.unit]; ok && p.unit != "week" && p.unit != "day" && p.amount != 0 {
+			dateOnly = false
+		}
+	}
+	return evalValue{kind: valueTime, to: to, dateOnly: dateOnly}, nil
+}
+
+// difference return the period from start to end; like DtDiff, the calendar is walked
+// when both are dates without a time of day, unless WithCalendarDiff says otherwise
+func (e *evaluator) difference(start, end evalValue) []part {
+	useCalendar := start.dateOnly && end.dateOnly
+	if e.o.calendarDiff != nil {
+		useCalendar = *e.o.calendarDiff
+	}
+	return differenceParts(start.to.StdTime(), end.to.StdTime(), useCalendar)
+}
+
+// durationParts split a non-negative duration into weeks, days and clock units
+func durationParts(d time.Duration) []part {
+	week := fixedUnits["week"]
+	day := fixedUnits["day"]
+	parts := []part{{amount: int64(d / week), unit: "week"}, {amount: int64(d % week / day), unit: "day"}}
+	return append(parts, clockParts(d%day)...)
+}
+
+// multiply evaluate period × integer or integer × period
+func (e *evaluator) multiply(left, right evalValue, op evalToken) (evalValue, error) {
+	if left.kind == valueInteger && right.kind == valuePeriod {
+		left, right = right, left
+	}
+	switch {
+	case left.kind == valuePeriod && right.kind == valueInteger:
+		parts, ok := scalePartsChecked(left.parts, right.integer)
+		if !ok {
+			return evalValue{}, e.overflowError(op)
+		}
+		return evalValue{kind: valuePeriod, parts: parts}, nil
+	case left.kind == valueInteger && right.kind == valueInteger:
+		n, ok := mulInt64(left.integer, right.integer)
+		if !ok {
+			return evalValue{}, e.overflowError(op)
+		}
+		return evalValue{kind: valueInteger, integer: n}, nil
+	}
+	return evalValue{}, e.errorf(op.offset, "[Eval] Invalid operation at offset %d: %s %s %s", op.offset, kindName(left), op.text, kindName(right))
+}
+
+// scaleParts return a copy of parts with every amount multiplied by n
+func scaleParts(parts []part, n int64) []part {
+	scaled := make([]part, len(parts))
+	for i, p := range parts {
+		scaled[i] = part{amount: p.amount * n, unit: p.unit, fraction: p.fraction * float64(n)}.normalize()
+	}
+	return scaled
+}
+
+// scalePartsChecked return scaleParts(parts, n), or false when an amount does not fit in an int64
+func scalePartsChecked(parts []part, n int64) ([]part, bool) {
+	for _, p := range parts {
+		amount, ok := mulInt64(p.amount, n)
+		if ok {
+			_, ok = addInt64(amount, int64(math.Trunc(p.fraction*float64(n))))
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return scaleParts(parts, n), true
+}
+
+// sumFits return true when the sum of each unit of all the parts fits in an int64
+func sumFits(all ...[]part) bool {
+	sums := make(map[string]int64)
+	for _, parts := range all {
+		for _, p := range parts {
+			sum, ok := addInt64(sums[p.unit], p.amount)
+			if !ok {
+				return false
+			}
+			sums[p.unit] = sum
+		}
+	}
+	return true
+}
+
+// mulInt64 return a*b, and false when the product does not fit in an int64
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return c, false
+	}
+	return c, true
+}
+
+// addInt64 return a+b, and false when the sum does not fit in an int64
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	if (c > a) != (b > 0) {
+		return c, false
+	}
+	return c, true
+}
+
+// combineParts sum the amounts of each unit and return them from the largest unit to the smallest
+// units which add up to zero are dropped
+func combineParts(all ...[]part) []part {
+	sums := make(map[string]part)
+	for _, parts := range all {
+		for _, p := range parts {
+			sum := sums[p.unit]
+			sums[p.unit] = part{amount: sum.amount + p.amount, unit: p.unit, fraction: sum.fraction + p.fraction}.normalize()
+		}
+	}
+	var combined []part
+	for _, unit := range unitOrder {
+		if sum := sums[unit]; sum.amount != 0 || sum.fraction != 0 {
+			combined = append(combined, sum)
+		}
+	}
+	return combined
+}
+
+// kindName return the name of the kind of v for error messages
+func kindName(v evalValue) string {
+	switch v.kind {
+	case valueTime:
+		return "datetime"
+	case valuePeriod:
+		return "period"
+	}
+	return "integer"
+}
@@ -0,0 +1,66 @@
+package dtdiff
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testEval(t *testing.T, expression, correct string) {
+	computed, err := Eval(expression, WithLocation(time.UTC))
+	if err != nil {
+		t.Error(err)
+	}
+	if computed != correct {
+		t.Errorf("[expression: %v] [computed: %v] != [correct: %v]", expression, computed, correct)
+	}
+}
+
+func TestEvalDateTimeAndPeriod(t *testing.T) {
+	testEval(t, "2024-01-01 + 3D - 2h", "2024-01-03 22:00:00 +0000 UTC")
+	testEval(t, "2024-01-31 + 1 month", "2024-03-02 00:00:00 +0000 UTC")
+	testEval(t, "1W + 2024-01-01", "2024-01-08 00:00:00 +0000 UTC")
+	testEval(t, "2024-12-20 + 5 business days - 1 business day", "2024-12-26 00:00:00 +0000 UTC")
+	testEval(t, "2024-03-15 + 1M-2D", "2024-04-13 00:00:00 +0000 UTC")
+	testEval(t, "20240101 + 1D", "2024-01-02 00:00:00 +0000 UTC")
+}
+
+func TestEvalDifference(t *testing.T) {
+	testEval(t, "2024-06-01 - 2024-01-01", "5 months")
+	testEval(t, "2024-01-01 - 2024-06-01", "-5 months")
+	testEval(t, "2024-01-01T10:00:00Z - 2024-01-01T08:30:00Z", "1 hour 30 minutes")
+	testEval(t, "(2024-06-01 - 2024-01-01) * 2", "10 months")
+	testEval(t, "2024-01-01 + (2024-06-01 - 2024-01-01) × 2", "2024-11-01 00:00:00 +0000 UTC")
+	testEval(t, "2000-01-01T00:00:00Z - 1990-01-01T00:00:00Z", "521 weeks 5 days")
+
+	// more than a time.Duration can hold
+	testEval(t, "2024-01-01T00:00:00Z - 1600-01-01T00:00:00Z", "22123 weeks 2 days")
+	testEval(t, "1600-01-01T00:00:00.500Z - 2024-01-01T00:00:00Z", "-22123 weeks -1 day -23 hours -59 minutes -59 seconds -500 milliseconds")
+	testEval(t, "1600-01-01T00:00:00Z + (2024-01-01T00:00:00Z - 1600-01-01T00:00:00Z)", "2024-01-01 00:00:00 +0000 UTC")
+}
+
+func TestEvalPeriods(t *testing.T) {
+	testEval(t, "1 month + 2 weeks - 3 days", "1 month 2 weeks -3 days")
+	testEval(t, "3 * (1M-2D)", "3 months -6 days")
+	testEval(t, "1h + 1h - 2h", "0 seconds")
+	testEval(t, "2 * 3 * 1D", "6 days")
+}
+
+func TestEvalErrors(t *testing.T) {
+	for _, expression := range []string{"", "2024-01-01 + 2024-01-02", "2024-01-01 +", "(2024-01-01", "2024-01-01)",
+		"bogus + 1D", "2 * 3", "2024-01-01 * 2", "1D - 2024-01-01"} {
+		if _, err := Eval(expression); err == nil {
+			t.Errorf("[expression: %v] an error was expected", expression)
+		}
+	}
+}
+
+func TestEvalOverflow(t *testing.T) {
+	for _, expression := range []string{"1D * 9223372036854775807 * 2", "2024-01-01 + 1D * 9223372036854775807 * 2",
+		"4611686018427387904 * 2 * 1D", "1D * 4611686018427387903 * 2 + 2D", "1.5D * 9223372036854775807"} {
+		if _, err := Eval(expression); !errors.Is(err, ErrRangeOverflow) {
+			t.Errorf("[expression: %v] [computed: %v] is not: [correct: %v]", expression, err, ErrRangeOverflow)
+		}
+	}
+	testEval(t, "1D * 4611686018427387903 * 2", "9223372036854775806 days")
+}
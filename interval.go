@@ -0,0 +1,209 @@
+package dtdiff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minutePrecisionRegexp matches an ISO 8601 datetime without seconds, such as 2024-01-01T00:00Z
+var minutePrecisionRegexp = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2})(Z|[+-]\d{2}:\d{2})?$`)
+
+// Interval is an ISO 8601 time interval, given as start/end, start/period or period/end,
+// such as "2024-01-01/P1M"; a repeating interval such as "R5/2024-01-01/P1D" has Repetitions > 0
+// Start, End and Period hold the values that were given and are otherwise empty, except that
+// the Period of a repeating start/end interval is the ISO 8601 duration between them
+type Interval struct {
+	Start       string
+	End         string
+	Period      string
+	Repetitions int
+}
+
+// ParseInterval return the interval described by "interval", such as
+// "2024-01-01T00:00Z/2024-03-01T00:00Z", "2024-01-01/P1M", "P1M/2024-03-01" or "R5/2024-01-01/P1D"
+// the period must be an ISO 8601 duration, the start and end may be any value accepted by Add
+// or an ISO 8601 datetime without seconds, such as 2024-01-01T00:00Z;
+// unbounded repetitions such as "R/2024-01-01/P1D" are not supported
+func ParseInterval(interval string, opts ...Option) (Interval, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Interval{}, err
+	}
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("[ParseInterval] Invalid interval %q: %s", interval, fmt.Sprintf(format, args...))
+	}
+
+	var iv Interval
+	fields := strings.Split(strings.TrimSpace(interval), "/")
+	if len(fields) == 3 {
+		if !strings.HasPrefix(strings.ToUpper(fields[0]), "R") {
+			return iv, invalid("expected R<n> before the first /")
+		}
+		n, err := strconv.Atoi(fields[0][1:])
+		if err != nil || n < 1 {
+			return iv, invalid("expected a number of repetitions of at least 1, such as R5, found %q", fields[0])
+		}
+		iv.Repetitions = n
+		fields = fields[1:]
+	}
+	if len(fields) != 2 {
+		return iv, invalid("expected start/end, start/period or period/end")
+	}
+
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if len(field) == 0 {
+			return iv, invalid("the value on side %d of / is empty", i+1)
+		}
+		if isISO8601Period(field) {
+			if _, err := parsePeriod(field, false); err != nil {
+				return iv, err
+			}
+			if len(iv.Period) > 0 {
+				return iv, invalid("only one side can be a period")
+			}
+			iv.Period = field
+			continue
+		}
+		// add the seconds, which are required by the parser
+		field = minutePrecisionRegexp.ReplaceAllString(field, "${1}:00${2}")
+		if _, err := parseFrom(field, "", o); err != nil {
+			return iv, invalid("unable to parse %q", field)
+		}
+		if i == 0 {
+			iv.Start = field
+		} else {
+			iv.End = field
+		}
+	}
+
+	if iv.Repetitions > 0 && len(iv.Period) == 0 {
+		dt := New(iv.Start, iv.End)
+		dt.opts = o
+		dt.SetISO8601(true)
+		if iv.Period, _, err = dt.DtDiff(); err != nil {
+			return iv, err
+		}
+	}
+	return iv, nil
+}
+
+// isISO8601Period return true when "value" starts with P, such as "P1D" or "PT5M"
+func isISO8601Period(value string) bool {
+	return strings.HasPrefix(strings.ToUpper(value), "P")
+}
+
+// Bounds return the start and end of the interval, computing the missing one from the period
+// a computed value keeps a date-only start or end as a date when no time of day is added
+func (iv Interval) Bounds(opts ...Option) (string, string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return "", "", err
+	}
+	switch {
+	case len(iv.Start) > 0 && len(iv.End) > 0:
+		return iv.Start, iv.End, nil
+	case len(iv.Start) > 0:
+		end, err := intervalBound(iv.Start, iv.Period, 0, o)
+		return iv.Start, end, err
+	case len(iv.End) > 0:
+		start, err := intervalBound(iv.End, iv.Period, 1, o)
+		return start, iv.End, err
+	}
+	return "", "", fmt.Errorf("[Bounds] Invalid interval: neither start nor end was given")
+}
+
+// intervalBound Add (index==0) or Sub (index==1) period to "from" and return the result as
+// a date or RFC3339 datetime, which can be given to New
+func intervalBound(from, period string, index int, o options) (string, error) {
+	field := FieldStart
+	if index == 1 {
+		field = FieldEnd
+	}
+	to, err := parseFrom(from, field, o)
+	if err != nil {
+		return "", err
+	}
+	to, err = applyPeriod(to, period, index, o)
+	if err != nil {
+		return "", err
+	}
+	t := to.StdTime()
+	if isDateOnly(from) && t.Equal(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())) {
+		return t.Format(time.DateOnly), nil
+	}
+	return t.Format(time.RFC3339Nano), nil
+}
+
+// Recurrence return each date/time of a repeating interval; a start is repeatedly added to
+// with AddWithRecurrence and an end is repeatedly subtracted from with SubWithRecurrence
+func (iv Interval) Recurrence(opts ...Option) ([]string, error) {
+	if iv.Repetitions < 1 {
+		return nil, fmt.Errorf("[Recurrence] Invalid interval: %s; it does not repeat", iv)
+	}
+	if len(iv.Start) > 0 {
+		return AddWithRecurrence(iv.Start, iv.Period, iv.Repetitions, opts...)
+	}
+	return SubWithRecurrence(iv.End, iv.Period, iv.Repetitions, opts...)
+}
+
+// String return the interval in ISO 8601 notation, such as "R5/2024-01-01/P1D"
+// a repeating interval is written with its period instead of its end
+func (iv Interval) String() string {
+	var fields []string
+	if iv.Repetitions > 0 {
+		fields = append(fields, fmt.Sprintf("R%d", iv.Repetitions))
+	}
+	switch {
+	case len(iv.Start) > 0 && len(iv.End) > 0 && (iv.Repetitions == 0 || len(iv.Period) == 0):
+		fields = append(fields, iv.Start, iv.End)
+	case len(iv.Start) > 0:
+		fields = append(fields, iv.Start, iv.Period)
+	default:
+		fields = append(fields, iv.Period, iv.End)
+	}
+	return strings.Join(fields, "/")
+}
+
+// PeriodToISO8601 return "period", such as "1 month 2 days" or "1M2D", as an ISO 8601 duration such as "P1M2D"
+// business days, decimal amounts and amounts with different signs can not be written as an ISO 8601 duration
+func PeriodToISO8601(period string, opts ...Option) (string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	parts, err := parsePeriod(period, o.repeatedUnits)
+	if err != nil {
+		return "", err
+	}
+	var sign int64
+	for _, p := range parts {
+		if p.unit == businessDay || p.fraction != 0 {
+			message := fmt.Sprintf("[PeriodToISO8601] Invalid period: %s; business days and decimal amounts can not be written as an ISO 8601 duration", period)
+			return "", periodError(ErrInvalidPeriod, period, -1, message)
+		}
+		if p.amount == 0 {
+			continue
+		}
+		if sign != 0 && (p.amount < 0) != (sign < 0) {
+			message := fmt.Sprintf("[PeriodToISO8601] Invalid period: %s; amounts with different signs can not be written as an ISO 8601 duration", period)
+			return "", periodError(ErrInvalidPeriod, period, -1, message)
+		}
+		sign = p.amount
+	}
+	negative := sign < 0
+	if negative {
+		parts = scaleParts(parts, -1)
+	}
+	return formatISO8601(parts, negative), nil
+}
+
+// Interval return the start and end in ISO 8601 interval notation, such as
+// "2024-01-01T00:00:00Z/2024-03-01T00:00:00Z", in the output location when one was set
+// this is only valid after calling DtDiff, Total or BusinessDays
+func (dt *DtDiff) Interval() Interval {
+	return Interval{Start: dt.StartTime().Format(time.RFC3339Nano), End: dt.EndTime().Format(time.RFC3339Nano)}
+}
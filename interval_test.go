@@ -0,0 +1,146 @@
+package dtdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func testIntervalBounds(t *testing.T, interval, correctStart, correctEnd string) {
+	iv, err := ParseInterval(interval)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	start, end, err := iv.Bounds()
+	if err != nil {
+		t.Error(err)
+	}
+	if start != correctStart || end != correctEnd {
+		t.Errorf("[interval: %v] [computed: %v/%v] != [correct: %v/%v]", interval, start, end, correctStart, correctEnd)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	testIntervalBounds(t, "2024-01-01T00:00Z/2024-03-01T00:00Z", "2024-01-01T00:00:00Z", "2024-03-01T00:00:00Z")
+	testIntervalBounds(t, "2024-01-01/P1M", "2024-01-01", "2024-02-01")
+	testIntervalBounds(t, "P1M/2024-03-01", "2024-02-01", "2024-03-01")
+	testIntervalBounds(t, "2024-01-01T00:00:00Z/PT12H", "2024-01-01T00:00:00Z", "2024-01-01T12:00:00Z")
+
+	iv, err := ParseInterval("2024-01-01/P1M")
+	if err != nil {
+		t.Error(err)
+	}
+	start, end, _ := iv.Bounds()
+	format, _, err := New(start, end).DtDiff()
+	if err != nil {
+		t.Error(err)
+	}
+	if format != "1 month" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "1 month")
+	}
+}
+
+func TestRepeatingInterval(t *testing.T) {
+	tests := []struct {
+		interval string
+		correct  []string
+	}{
+		{"R3/2024-01-01T00:00:00Z/P1D", []string{"2024-01-02 00:00:00", "2024-01-03 00:00:00", "2024-01-04 00:00:00"}},
+		{"R2/P1W/2024-03-01T00:00:00Z", []string{"2024-02-23 00:00:00", "2024-02-16 00:00:00"}},
+		{"R2/2024-01-01/2024-01-15", []string{"2024-01-15 00:00:00", "2024-01-29 00:00:00"}},
+	}
+	for _, test := range tests {
+		iv, err := ParseInterval(test.interval)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		all, err := iv.Recurrence()
+		if err != nil {
+			t.Error(err)
+		}
+		if len(all) != len(test.correct) {
+			t.Errorf("[interval: %v] [computed: %v] != [correct: %v]", test.interval, all, test.correct)
+			continue
+		}
+		for i := range all {
+			if !strings.Contains(all[i], test.correct[i]) {
+				t.Errorf("[interval: %v] [computed: %v] does not contain: [correct: %v]", test.interval, all[i], test.correct[i])
+			}
+		}
+	}
+}
+
+func TestParseIntervalErrors(t *testing.T) {
+	tests := []struct {
+		interval string
+		message  string
+	}{
+		{"2024-01-01", "expected start/end"},
+		{"P1D/P2D", "only one side can be a period"},
+		{"R/2024-01-01/P1D", "expected a number of repetitions"},
+		{"X5/2024-01-01/P1D", "expected R<n>"},
+		{"2024-01-01/", "side 2 of / is empty"},
+		{"2024-01-01/P1H", "unknown designator"},
+		{"yesterday-ish/P1D", "unable to parse"},
+	}
+	for _, test := range tests {
+		_, err := ParseInterval(test.interval)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("[interval: %v] [computed: %v] does not contain: [correct: %v]", test.interval, err, test.message)
+		}
+	}
+}
+
+func TestFormatInterval(t *testing.T) {
+	tests := []struct {
+		interval Interval
+		correct  string
+	}{
+		{Interval{Start: "2024-01-01", End: "2024-03-01"}, "2024-01-01/2024-03-01"},
+		{Interval{Start: "2024-01-01", Period: "P1D", Repetitions: 5}, "R5/2024-01-01/P1D"},
+		{Interval{End: "2024-03-01", Period: "P1W", Repetitions: 2}, "R2/P1W/2024-03-01"},
+		{Interval{Period: "P1M", End: "2024-03-01"}, "P1M/2024-03-01"},
+	}
+	for _, test := range tests {
+		if computed := test.interval.String(); computed != test.correct {
+			t.Errorf("[computed: %v] != [correct: %v]", computed, test.correct)
+		}
+	}
+
+	dt := New("2024-01-01T00:00:00Z", "2024-03-01T12:00:00Z")
+	if _, _, err := dt.DtDiff(); err != nil {
+		t.Error(err)
+	}
+	if computed := dt.Interval().String(); computed != "2024-01-01T00:00:00Z/2024-03-01T12:00:00Z" {
+		t.Errorf("[computed: %v] != [correct: %v]", computed, "2024-01-01T00:00:00Z/2024-03-01T12:00:00Z")
+	}
+}
+
+func TestPeriodToISO8601(t *testing.T) {
+	tests := []struct {
+		period  string
+		correct string
+	}{
+		{"1 month 2 days", "P1M2D"},
+		{"1Y2M3D4h5m6s7ms", "P1Y2M3DT4H5M6.007S"},
+		{"2W", "P2W"},
+		{"1W2D", "P9D"},
+		{"-1D-2h", "-P1DT2H"},
+		{"P1DT2H", "P1DT2H"},
+	}
+	for _, test := range tests {
+		computed, err := PeriodToISO8601(test.period)
+		if err != nil {
+			t.Error(err)
+		}
+		if computed != test.correct {
+			t.Errorf("[period: %v] [computed: %v] != [correct: %v]", test.period, computed, test.correct)
+		}
+	}
+	for _, period := range []string{"1M-2D", "2BD", "1.5h"} {
+		if _, err := PeriodToISO8601(period); err == nil {
+			t.Errorf("[period: %v] an error was expected", period)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package dtdiff
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// isoDesignators maps the designators of an ISO 8601 duration to units,
+// the date designators come before T and the time designators after it
+var isoDesignators = [2]map[rune]string{
+	{'Y': "year", 'M': "month", 'W': "week", 'D': "day"},
+	{'H': "hour", 'M': "minute", 'S': "second"},
+}
+
+// isoOrder is the required order of the units of an ISO 8601 duration
+var isoOrder = map[string]int{"year": 0, "month": 1, "week": 2, "day": 3, "hour": 4, "minute": 5, "second": 6}
+
+// isISO8601 return true when the lexer is at an ISO 8601 duration, such as "P1D" or "-PT5M"
+func (l *periodLexer) isISO8601() bool {
+	i := l.pos
+	if i < len(l.runes) && (l.runes[i] == '+' || l.runes[i] == '-') {
+		i++
+	}
+	return i < len(l.runes) && unicode.ToUpper(l.runes[i]) == 'P'
+}
+
+// iso8601 read an ISO 8601 duration such as P1Y2M3DT4H5M6S, P2W or -PT1.5S
+// an optional leading sign applies to every component; any component may have a
+// decimal portion, using either a period or a comma; designators are not case-sensitive
+func (l *periodLexer) iso8601() ([]part, error) {
+	sign := int64(1)
+	if r := l.runes[l.pos]; r == '+' || r == '-' {
+		if r == '-' {
+			sign = -1
+		}
+		l.pos++
+	}
+	l.pos++ // P
+
+	var parts []part
+	inTime, timeParts := false, 0
+	last := -1
+	for !l.done() {
+		if unicode.IsSpace(l.runes[l.pos]) {
+			l.skipSpace()
+			if !l.done() {
+				return nil, l.errorf(l.pos, "whitespace is not allowed within an ISO 8601 duration")
+			}
+			break
+		}
+		if unicode.ToUpper(l.runes[l.pos]) == 'T' {
+			if inTime {
+				return nil, l.errorf(l.pos, "T can only be given once")
+			}
+			inTime = true
+			l.pos++
+			continue
+		}
+
+		numberStart := l.pos
+		whole := l.digits()
+		var fraction float64
+		if !l.done() && (l.runes[l.pos] == '.' || l.runes[l.pos] == ',') {
+			l.pos++
+			decimals := l.digits()
+			if len(decimals) == 0 {
+				return nil, l.errorf(l.pos, "expected a digit after the decimal point")
+			}
+			fraction, _ = strconv.ParseFloat("0."+decimals, 64)
+		} else if len(whole) == 0 {
+			return nil, l.errorf(l.pos, "expected a number, found %q", string(l.runes[l.pos]))
+		}
+		if l.done() {
+			return nil, l.errorf(l.pos, "expected a designator after the number")
+		}
+
+		designator := unicode.ToUpper(l.runes[l.pos])
+		timeIndex := 0
+		if inTime {
+			timeIndex = 1
+		}
+		unit, ok := isoDesignators[timeIndex][designator]
+		if !ok {
+			return nil, l.errorf(l.pos, "unknown designator %q", string(l.runes[l.pos]))
+		}
+		if isoOrder[unit] == last {
+			return nil, l.kindErrorf(ErrDuplicateUnit, l.pos, "designator %q is repeated", string(l.runes[l.pos]))
+		}
+		if isoOrder[unit] < last {
+			return nil, l.errorf(l.pos, "designator %q is out of order", string(l.runes[l.pos]))
+		}
+		last = isoOrder[unit]
+		l.pos++
+
+		var n int64
+		if len(whole) > 0 {
+			var err error
+			if n, err = strconv.ParseInt(whole, 10, 64); err != nil {
+				return nil, l.kindErrorf(ErrRangeOverflow, numberStart, "amount out of range")
+			}
+		}
+		parts = append(parts, part{amount: sign * n, unit: unit, fraction: float64(sign) * fraction})
+		if inTime {
+			timeParts++
+		}
+	}
+
+	if len(parts) == 0 {
+		return nil, l.errorf(l.pos, "expected at least one component")
+	}
+	if inTime && timeParts == 0 {
+		return nil, l.errorf(l.pos, "expected a time component after T")
+	}
+	return parts, nil
+}
+
+// formatISO8601 return parts as an ISO 8601 duration, such as P1Y2M3DT4H5M6.5S
+// weeks are converted to days unless they are the only unit, since ISO 8601 does not combine them
+// with other units; sub-second units become a decimal portion of the seconds, and a negative
+// duration starts with a minus sign
+func formatISO8601(parts []part, negative bool) string {
+	amounts := make(map[string]int64)
+	for _, p := range parts {
+		amounts[p.unit] += p.amount
+	}
+	weeksOnly := amounts["week"] != 0
+	for unit, amount := range amounts {
+		if unit != "week" && amount != 0 {
+			weeksOnly = false
+		}
+	}
+	if weeksOnly {
+		sign := ""
+		if negative {
+			sign = "-"
+		}
+		return sign + "P" + strconv.FormatInt(amounts["week"], 10) + "W"
+	}
+	amounts["day"] += 7 * amounts["week"]
+	nanos := amounts["second"]*int64(time.Second) + amounts["millisecond"]*int64(time.Millisecond) +
+		amounts["microsecond"]*int64(time.Microsecond) + amounts["nanosecond"]
+
+	var b strings.Builder
+	if negative {
+		b.WriteString("-")
+	}
+	b.WriteString("P")
+	for _, d := range []struct {
+		unit       string
+		designator string
+	}{{"year", "Y"}, {"month", "M"}, {"day", "D"}} {
+		if amounts[d.unit] != 0 {
+			b.WriteString(strconv.FormatInt(amounts[d.unit], 10) + d.designator)
+		}
+	}
+
+	var clock strings.Builder
+	for _, d := range []struct {
+		unit       string
+		designator string
+	}{{"hour", "H"}, {"minute", "M"}} {
+		if amounts[d.unit] != 0 {
+			clock.WriteString(strconv.FormatInt(amounts[d.unit], 10) + d.designator)
+		}
+	}
+	if nanos != 0 {
+		seconds := strconv.FormatInt(nanos/int64(time.Second), 10)
+		if rest := nanos % int64(time.Second); rest != 0 {
+			seconds += strings.TrimRight("."+strconv.FormatInt(rest+int64(time.Second), 10)[1:], "0")
+		}
+		clock.WriteString(seconds + "S")
+	}
+	if clock.Len() > 0 {
+		b.WriteString("T" + clock.String())
+	}
+	if b.Len() <= 2 {
+		return "PT0S"
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package dtdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func testISO8601(t *testing.T, start, end string, calendarDiff bool, correct string) {
+	dt := New(start, end)
+	dt.SetISO8601(true)
+	dt.SetCalendarDiff(calendarDiff)
+	format, _, err := dt.DtDiff()
+	if err != nil {
+		t.Error(err)
+	}
+	if format != correct {
+		t.Errorf("[computed: %v] != [correct: %v]", format, correct)
+	}
+}
+
+func TestParseISO8601(t *testing.T) {
+	testParsePeriod(t, "P1Y2M3DT4H5M6S", false, "1 year 2 months 3 days 4 hours 5 minutes 6 seconds")
+	testParsePeriod(t, "P2W", false, "2 weeks")
+	testParsePeriod(t, "PT1M", false, "1 minute")
+	testParsePeriod(t, "PT1.5S", false, "1.5 seconds")
+	testParsePeriod(t, "PT0,5H", false, "0.5 hours")
+	testParsePeriod(t, "-P1DT12H", false, "-1 day -12 hours")
+	testParsePeriod(t, " p1dt2h ", false, "1 day 2 hours")
+}
+
+func TestParseISO8601Errors(t *testing.T) {
+	tests := []struct {
+		period string
+		offset string
+	}{
+		{"P", "at offset 1: expected at least one component"},
+		{"P1DT", "at offset 4: expected a time component after T"},
+		{"P1H", "at offset 2: unknown designator \"H\""},
+		{"PT1D", "at offset 3: unknown designator \"D\""},
+		{"P1D1Y", "at offset 4: designator \"Y\" is out of order"},
+		{"P1", "at offset 2: expected a designator"},
+		{"P1D 2H", "at offset 4: whitespace is not allowed"},
+		{"P1DTT1H", "at offset 4: T can only be given once"},
+		{"PT1.H", "at offset 4: expected a digit after the decimal point"},
+	}
+	for _, test := range tests {
+		err := ValidatePeriod(test.period)
+		if err == nil || !strings.Contains(err.Error(), test.offset) {
+			t.Errorf("[period: %v] [computed: %v] does not contain: [correct: %v]", test.period, err, test.offset)
+		}
+	}
+}
+
+func TestAddSubISO8601(t *testing.T) {
+	from := "2024-01-15T10:00:00Z"
+	testAddSubContains(t, from, "P1M2DT3H", "2024-02-17 13:00:00", "2023-12-13 07:00:00")
+	testAddSubContains(t, from, "PT1.5H", "2024-01-15 11:30:00", "2024-01-15 08:30:00")
+	testAddSubContains(t, from, "-P1W", "2024-01-08 10:00:00", "2024-01-22 10:00:00")
+	testAddSubWithRecurrence(t, from, "P1D", []string{"2024-01-16 10:00:00", "2024-01-17 10:00:00"}, []string{"2024-01-14 10:00:00", "2024-01-13 10:00:00"}, 2)
+}
+
+func TestFormatISO8601(t *testing.T) {
+	testISO8601(t, "2024-01-01", "2025-03-04", true, "P1Y2M3D")
+	testISO8601(t, "2024-01-01", "2024-01-01", true, "PT0S")
+	testISO8601(t, "2024-03-04", "2024-01-01", true, "-P2M3D")
+	testISO8601(t, "2024-01-01 10:00:00", "2024-01-03 12:30:01.25", false, "P2DT2H30M1.25S")
+	testISO8601(t, "2024-01-01 00:00:00", "2024-01-22 00:00:00.000001", false, "P21DT0.000001S")
+	testISO8601(t, "2024-01-03 12:30:00", "2024-01-01 00:00:00", false, "-P2DT12H30M")
+}
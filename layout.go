@@ -0,0 +1,146 @@
+package dtdiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layoutPresets are the names accepted by WithLayout in addition to a Go reference layout
+var layoutPresets = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"iso-date":    time.DateOnly,
+	"datetime":    time.DateTime,
+	"unix":        "unix",
+	"unixms":      "unixms",
+}
+
+// strftimeDirectives maps each strftime directive to the equivalent Go layout
+var strftimeDirectives = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2", 'j': "002",
+	'H': "15", 'I': "03", 'M': "04", 'S': "05", 'p': "PM", 'f': ".000000",
+	'b': "Jan", 'h': "Jan", 'B': "January", 'a': "Mon", 'A': "Monday",
+	'Z': "MST", 'z': "-0700", 'F': "2006-01-02", 'T': "15:04:05", 'R': "15:04",
+	'D': "01/02/06", 'c': "Mon Jan _2 15:04:05 2006",
+}
+
+// WithLayout format results with a Go reference layout such as "2006-01-02 15:04"
+// or one of these presets: rfc3339, rfc3339nano, rfc1123, rfc1123z, iso-date, datetime, unix, unixms
+func WithLayout(layout string) Option {
+	return func(o *options) {
+		if preset, ok := layoutPresets[strings.ToLower(layout)]; ok {
+			layout = preset
+		}
+		o.layout = layout
+		o.strftime = ""
+	}
+}
+
+// WithStrftime format results with a C strftime format such as "%Y-%m-%d %H:%M"
+// %s returns the Unix time in seconds and %% returns a literal percent sign
+func WithStrftime(format string) Option {
+	return func(o *options) {
+		o.layout = ""
+		o.strftime = format
+		if err := validateStrftime(format); err != nil {
+			o.err = err
+		}
+	}
+}
+
+// WithLayouts parse dates with these Go reference layouts, such as "02.01.2006 15:04", before trying
+// the built-in formats; the presets of WithLayout are also accepted; values without an offset or
+// zone are interpreted in the parse location
+func WithLayouts(layouts ...string) Option {
+	return func(o *options) {
+		o.inputLayouts = nil
+		for _, layout := range layouts {
+			if preset, ok := layoutPresets[strings.ToLower(layout)]; ok {
+				layout = preset
+			}
+			o.inputLayouts = append(o.inputLayouts, layout)
+		}
+	}
+}
+
+// parseLayouts return value parsed with the first layout given with WithLayouts that matches
+func (o options) parseLayouts(value string) (time.Time, bool) {
+	for _, layout := range o.inputLayouts {
+		switch layout {
+		case "unix", "unixms":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			if layout == "unix" {
+				return time.Unix(n, 0).In(o.parseLocation()), true
+			}
+			return time.UnixMilli(n).In(o.parseLocation()), true
+		}
+		if t, err := time.ParseInLocation(layout, value, o.parseLocation()); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatLayout return t formatted with a Go layout or the unix and unixms presets
+func formatLayout(t time.Time, layout string) string {
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixms":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	return t.Format(layout)
+}
+
+// validateStrftime ensure every directive in "format" is supported
+func validateStrftime(format string) error {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 == len(format) {
+			return fmt.Errorf("[WithStrftime] Invalid format: %s; trailing %%", format)
+		}
+		i++
+		if _, ok := strftimeDirectives[format[i]]; !ok && format[i] != 's' && format[i] != '%' && format[i] != 'n' && format[i] != 't' {
+			return fmt.Errorf("[WithStrftime] Invalid format: %s; unsupported directive %%%c", format, format[i])
+		}
+	}
+	return nil
+}
+
+// strftime return t formatted with a validated strftime format
+// literal text is copied as is, so it is never mistaken for part of a Go layout
+func strftime(t time.Time, format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'f':
+			// microseconds without the leading dot
+			b.WriteString(t.Format(".000000")[1:])
+		case '%':
+			b.WriteByte('%')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteString(t.Format(strftimeDirectives[format[i]]))
+		}
+	}
+	return b.String()
+}
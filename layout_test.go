@@ -0,0 +1,82 @@
+package dtdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func testLayout(t *testing.T, from, period string, opt Option, correct string) {
+	future, err := Add(from, period, opt)
+	if err != nil {
+		t.Error(err)
+	}
+	if future != correct {
+		t.Errorf("[from: %v] [computed: %v] != [correct: %v]", from, future, correct)
+	}
+}
+
+func TestWithLayoutPresets(t *testing.T) {
+	from := "2024-01-31T13:05:06.5Z"
+	testLayout(t, from, "1D", WithLayout("rfc3339"), "2024-02-01T13:05:06Z")
+	testLayout(t, from, "1D", WithLayout("RFC3339Nano"), "2024-02-01T13:05:06.5Z")
+	testLayout(t, from, "1D", WithLayout("rfc1123"), "Thu, 01 Feb 2024 13:05:06 UTC")
+	testLayout(t, from, "1D", WithLayout("iso-date"), "2024-02-01")
+	testLayout(t, from, "1D", WithLayout("unix"), "1706792706")
+	testLayout(t, from, "1D", WithLayout("unixms"), "1706792706500")
+	testLayout(t, from, "1D", WithLayout("02 Jan 06 15:04"), "01 Feb 24 13:05")
+}
+
+func TestWithStrftime(t *testing.T) {
+	from := "2024-01-31T13:05:06.123456Z"
+	testLayout(t, from, "1D", WithStrftime("%Y-%m-%d %H:%M:%S"), "2024-02-01 13:05:06")
+	testLayout(t, from, "1D", WithStrftime("%A %B %e %I:%M %p"), "Thursday February  1 01:05 PM")
+	testLayout(t, from, "1D", WithStrftime("Mon %j %f %s 100%%"), "Mon 032 123456 1706792706 100%")
+
+	if _, err := Add(from, "1D", WithStrftime("%Y-%Q")); err == nil {
+		t.Error("expected an error for an unsupported directive")
+	}
+	if _, err := Add(from, "1D", WithStrftime("%Y-%")); err == nil {
+		t.Error("expected an error for a trailing percent sign")
+	}
+}
+
+func TestLayoutWithRecurrence(t *testing.T) {
+	all, err := AddWithRecurrence("2024-01-31T00:00:00Z", "1D", 2, WithLayout("iso-date"))
+	if err != nil {
+		t.Error(err)
+	}
+	if len(all) != 2 || all[0] != "2024-02-01" || all[1] != "2024-02-02" {
+		t.Errorf("[computed: %v] != [correct: %v]", all, []string{"2024-02-01", "2024-02-02"})
+	}
+
+	all, err = SubUntil("2024-01-31T00:00:00Z", "2024-01-29T00:00:00Z", "1D", WithStrftime("%d"))
+	if err != nil {
+		t.Error(err)
+	}
+	if len(all) != 2 || all[0] != "30" || all[1] != "29" {
+		t.Errorf("[computed: %v] != [correct: %v]", all, []string{"30", "29"})
+	}
+}
+
+func TestWithLayouts(t *testing.T) {
+	future, err := Add("31.01.2024 10:00", "1D", WithLayouts("02.01.2006 15:04", "rfc3339"), WithLayout("rfc3339"))
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.HasPrefix(future, "2024-02-01T10:00:00") {
+		t.Errorf("[computed: %v] does not start with: [correct: %v]", future, "2024-02-01T10:00:00")
+	}
+
+	utc, _ := LoadLocation("UTC")
+	dt, err := NewWithOptions("1706695200", "02/01/2024 10:00", WithLayouts("unix", "01/02/2006 15:04"), WithLocation(utc))
+	if err != nil {
+		t.Error(err)
+	}
+	format, _, err := dt.DtDiff()
+	if err != nil {
+		t.Error(err)
+	}
+	if format != "1 day" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "1 day")
+	}
+}
@@ -0,0 +1,282 @@
+package dtdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the plural rules of a Locale, which decide when the singular form of a unit is used
+const (
+	// PluralOne use the singular form for exactly 1, such as English, German and Spanish
+	PluralOne string = "one"
+	// PluralZeroOne use the singular form for amounts below 2, including 0 and 1.5, such as French
+	PluralZeroOne string = "zero-one"
+	// PluralNone use the same form for every amount, such as Japanese
+	PluralNone string = "none"
+)
+
+// localeUnits are the units every Locale must name
+var localeUnits = []string{"year", "month", "week", "day", "business day", "hour", "minute", "second", "millisecond", "microsecond", "nanosecond"}
+
+// Locale holds the unit names used by the long output of DtDiff and Eval, such as "2 Jahre 3 Stunden"
+// brief and ISO 8601 output do not depend on the locale
+type Locale struct {
+	// Name is used with WithLocale, such as "de"
+	Name string `json:"name" yaml:"name"`
+	// Plural is one of PluralOne, PluralZeroOne or PluralNone; defaults to PluralOne
+	Plural string `json:"plural" yaml:"plural"`
+	// Decimal separates the whole amount from its decimal portion; defaults to "."
+	Decimal string `json:"decimal" yaml:"decimal"`
+	// Compact writes amounts and units without spaces, such as "2年3時間"
+	Compact bool `json:"compact" yaml:"compact"`
+	// Units maps each English unit name, such as "year" or "business day", to its singular and plural forms
+	Units map[string][]string `json:"units" yaml:"units"`
+}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]*Locale{}
+)
+
+func init() {
+	for _, locale := range builtinLocales {
+		locales[locale.Name] = locale
+	}
+}
+
+// builtinLocales are available without registering them
+var builtinLocales = []*Locale{
+	{Name: "en", Plural: PluralOne, Decimal: ".", Units: map[string][]string{
+		"year": {"year", "years"}, "month": {"month", "months"}, "week": {"week", "weeks"}, "day": {"day", "days"},
+		"business day": {"business day", "business days"}, "hour": {"hour", "hours"}, "minute": {"minute", "minutes"},
+		"second": {"second", "seconds"}, "millisecond": {"millisecond", "milliseconds"},
+		"microsecond": {"microsecond", "microseconds"}, "nanosecond": {"nanosecond", "nanoseconds"},
+	}},
+	{Name: "de", Plural: PluralOne, Decimal: ",", Units: map[string][]string{
+		"year": {"Jahr", "Jahre"}, "month": {"Monat", "Monate"}, "week": {"Woche", "Wochen"}, "day": {"Tag", "Tage"},
+		"business day": {"Werktag", "Werktage"}, "hour": {"Stunde", "Stunden"}, "minute": {"Minute", "Minuten"},
+		"second": {"Sekunde", "Sekunden"}, "millisecond": {"Millisekunde", "Millisekunden"},
+		"microsecond": {"Mikrosekunde", "Mikrosekunden"}, "nanosecond": {"Nanosekunde", "Nanosekunden"},
+	}},
+	{Name: "es", Plural: PluralOne, Decimal: ",", Units: map[string][]string{
+		"year": {"año", "años"}, "month": {"mes", "meses"}, "week": {"semana", "semanas"}, "day": {"día", "días"},
+		"business day": {"día hábil", "días hábiles"}, "hour": {"hora", "horas"}, "minute": {"minuto", "minutos"},
+		"second": {"segundo", "segundos"}, "millisecond": {"milisegundo", "milisegundos"},
+		"microsecond": {"microsegundo", "microsegundos"}, "nanosecond": {"nanosegundo", "nanosegundos"},
+	}},
+	{Name: "fr", Plural: PluralZeroOne, Decimal: ",", Units: map[string][]string{
+		"year": {"an", "ans"}, "month": {"mois", "mois"}, "week": {"semaine", "semaines"}, "day": {"jour", "jours"},
+		"business day": {"jour ouvré", "jours ouvrés"}, "hour": {"heure", "heures"}, "minute": {"minute", "minutes"},
+		"second": {"seconde", "secondes"}, "millisecond": {"milliseconde", "millisecondes"},
+		"microsecond": {"microseconde", "microsecondes"}, "nanosecond": {"nanoseconde", "nanosecondes"},
+	}},
+	{Name: "ja", Plural: PluralNone, Decimal: ".", Compact: true, Units: map[string][]string{
+		"year": {"年"}, "month": {"か月"}, "week": {"週間"}, "day": {"日"},
+		"business day": {"営業日"}, "hour": {"時間"}, "minute": {"分"},
+		"second": {"秒"}, "millisecond": {"ミリ秒"},
+		"microsecond": {"マイクロ秒"}, "nanosecond": {"ナノ秒"},
+	}},
+}
+
+// RegisterLocale validate "locale" and make it available to WithLocale
+// the name is stored in lowercase, and a locale with the same name as an existing one replaces it
+func RegisterLocale(locale *Locale) error {
+	if locale == nil {
+		return fmt.Errorf("[RegisterLocale] Invalid locale: the locale is nil")
+	}
+	if err := locale.validate(); err != nil {
+		return err
+	}
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[locale.Name] = locale
+	return nil
+}
+
+// LoadLocale read a Locale from a .json, .yaml or .yml file and register it, for example:
+//
+//	name: it
+//	plural: one
+//	decimal: ","
+//	units:
+//	  year: [anno, anni]
+//	  month: [mese, mesi]
+//	  ...
+//
+// every unit of the English locale must be given; a single form is used for every amount
+func LoadLocale(path string) (*Locale, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	locale, err := ReadLocale(file, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := RegisterLocale(locale); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return locale, nil
+}
+
+// ReadLocale read a Locale in the format given by "ext": .json, .yaml or .yml
+// the locale is not registered
+func ReadLocale(r io.Reader, ext string) (*Locale, error) {
+	var locale Locale
+	var err error
+	switch strings.ToLower(ext) {
+	case ".json":
+		err = json.NewDecoder(r).Decode(&locale)
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(r).Decode(&locale)
+	default:
+		return nil, fmt.Errorf("[ReadLocale] Unsupported locale file: %s; use .json, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[ReadLocale] %w", err)
+	}
+	return &locale, nil
+}
+
+// LookupLocale return the registered locale called "name", such as "de"
+func LookupLocale(name string) (*Locale, error) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	locale, ok := locales[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("[LookupLocale] Unknown locale: %s; available locales are: %s", name, strings.Join(localeNames(), ", "))
+	}
+	return locale, nil
+}
+
+// localeNames return the sorted names of the registered locales; the caller holds localesMu
+func localeNames() []string {
+	var names []string
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithLocale write the long output of DtDiff and Eval in the registered locale called "name",
+// such as "de" for "2 Jahre 3 Stunden"; the built-in locales are en, de, es, fr and ja
+func WithLocale(name string) Option {
+	return func(o *options) {
+		locale, err := LookupLocale(name)
+		if err != nil {
+			o.err = err
+			return
+		}
+		o.locale = locale
+	}
+}
+
+// validate return an error when a field of l is missing or invalid, and fill in the defaults
+func (l *Locale) validate() error {
+	l.Name = strings.ToLower(strings.TrimSpace(l.Name))
+	if len(l.Name) == 0 {
+		return fmt.Errorf("[RegisterLocale] Invalid locale: the name is empty")
+	}
+	switch l.Plural {
+	case "":
+		l.Plural = PluralOne
+	case PluralOne, PluralZeroOne, PluralNone:
+	default:
+		return fmt.Errorf("[RegisterLocale] Invalid locale %s: unknown plural rule: %s; use %s, %s or %s", l.Name, l.Plural, PluralOne, PluralZeroOne, PluralNone)
+	}
+	if len(l.Decimal) == 0 {
+		l.Decimal = "."
+	}
+	for _, unit := range localeUnits {
+		forms := l.Units[unit]
+		if len(forms) < 1 || len(forms) > 2 {
+			return fmt.Errorf("[RegisterLocale] Invalid locale %s: unit %q needs a singular and an optional plural form", l.Name, unit)
+		}
+	}
+	return nil
+}
+
+// unitName return the form of "unit" used for "amount"
+func (l *Locale) unitName(unit string, amount float64) string {
+	if unit == businessDay {
+		unit = "business day"
+	}
+	forms := l.Units[unit]
+	singular := false
+	switch l.Plural {
+	case PluralZeroOne:
+		singular = math.Abs(amount) < 2
+	case PluralNone:
+		singular = true
+	default:
+		singular = amount == 1 || amount == -1
+	}
+	if singular || len(forms) == 1 {
+		return forms[0]
+	}
+	return forms[1]
+}
+
+// formatParts return the non-zero parts in the long format of l, the same as formatParts does for English
+// a nil locale is English
+func (l *Locale) formatParts(parts []part, negative bool) string {
+	if l == nil {
+		return formatParts(parts, negative)
+	}
+	separator := " "
+	if l.Compact {
+		separator = ""
+	}
+	var words []string
+	for _, p := range parts {
+		if p.amount == 0 && p.fraction == 0 {
+			continue
+		}
+		if negative {
+			p = part{amount: -p.amount, unit: p.unit, fraction: -p.fraction}
+		}
+		amount := strings.Replace(p.formatAmount(), ".", l.Decimal, 1)
+		words = append(words, amount+separator+l.unitName(p.unit, float64(p.amount)+p.fraction))
+	}
+	if len(words) == 0 {
+		words = append(words, l.formatZero("second"))
+	}
+	return strings.Join(words, separator)
+}
+
+// formatZero return a zero amount of unit in the long format of l, such as "0 Stunden"
+// a nil locale is English
+func (l *Locale) formatZero(unit string) string {
+	if l == nil {
+		return formatZero(unit)
+	}
+	separator := " "
+	if l.Compact {
+		separator = ""
+	}
+	return "0" + separator + l.unitName(unit, 0)
+}
+
+// durafmtParts return the parts of d in the units used by durafmt: 365 day years, weeks, days and clock units
+// down to microseconds, along with whether d is negative
+func durafmtParts(d time.Duration) ([]part, bool) {
+	negative := d < 0
+	if negative {
+		d = -d
+	}
+	d = d.Truncate(time.Microsecond)
+	parts := []part{{amount: int64(d / yearLength), unit: "year"}}
+	return append(parts, durationParts(d%yearLength)...), negative
+}
@@ -0,0 +1,143 @@
+package dtdiff
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hako/durafmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLocaleYAML string = `name: it
+plural: one
+decimal: ","
+units:
+  year: [anno, anni]
+  month: [mese, mesi]
+  week: [settimana, settimane]
+  day: [giorno, giorni]
+  business day: [giorno lavorativo, giorni lavorativi]
+  hour: [ora, ore]
+  minute: [minuto, minuti]
+  second: [secondo, secondi]
+  millisecond: [millisecondo, millisecondi]
+  microsecond: [microsecondo, microsecondi]
+  nanosecond: [nanosecondo, nanosecondi]
+`
+
+func TestLocales(t *testing.T) {
+	tests := []struct {
+		lang    string
+		start   string
+		end     string
+		correct string
+	}{
+		{"de", "2024-01-01 00:00:00", "2025-12-31 23:59:59", "2 Jahre 23 Stunden 59 Minuten 59 Sekunden"},
+		{"de", "2024-01-01", "2025-02-02", "1 Jahr 1 Monat 1 Tag"},
+		{"fr", "2024-01-01", "2024-03-02", "2 mois 1 jour"},
+		{"fr", "12:00:00", "12:00:00", "0 seconde"},
+		{"es", "12:00:00", "15:01:00", "3 horas 1 minuto"},
+		{"ja", "2024-01-01 00:00:00", "2025-12-31 23:59:59", "2年23時間59分59秒"},
+		{"ja", "2024-03-01", "2024-01-01", "-2か月"},
+		{"EN", "12:00:00", "13:00:00", "1 hour"},
+	}
+	for _, test := range tests {
+		dt, err := NewWithOptions(test.start, test.end, WithLocale(test.lang))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		format, _, err := dt.DtDiff()
+		if err != nil {
+			t.Error(err)
+		}
+		if format != test.correct {
+			t.Errorf("[lang: %v] [computed: %v] != [correct: %v]", test.lang, format, test.correct)
+		}
+	}
+
+	// brief output does not depend on the locale
+	dt, _ := NewWithOptions("2024-01-01 00:00:00", "2025-12-31 23:59:59", WithLocale("de"), WithBrief())
+	if format, _, _ := dt.DtDiff(); format != "2Y23h59m59s" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "2Y23h59m59s")
+	}
+
+	result, err := Eval("1.5h * 3", WithLocale("de"))
+	if err != nil {
+		t.Error(err)
+	}
+	if result != "4,5 Stunden" {
+		t.Errorf("[computed: %v] != [correct: %v]", result, "4,5 Stunden")
+	}
+
+	if _, err := NewWithOptions("12:00", "13:00", WithLocale("xx")); err == nil || !strings.Contains(err.Error(), "de, en, es, fr, ja") {
+		t.Errorf("[computed: %v] should list the available locales", err)
+	}
+}
+
+func TestDurafmtParts(t *testing.T) {
+	english, _ := LookupLocale("en")
+	for _, d := range []time.Duration{time.Second, 90 * time.Minute, 400*24*time.Hour + 1234567*time.Microsecond, 8*24*time.Hour + 1500*time.Nanosecond} {
+		correct := fmt.Sprintf("%v", durafmt.Parse(d))
+		if computed := english.formatParts(durafmtParts(d)); computed != correct {
+			t.Errorf("[duration: %v] [computed: %v] != [correct: %v]", d, computed, correct)
+		}
+	}
+	// unlike durafmt, every unit of a negative duration has a minus sign
+	if computed := english.formatParts(durafmtParts(-36 * time.Hour)); computed != "-1 day -12 hours" {
+		t.Errorf("[computed: %v] != [correct: %v]", computed, "-1 day -12 hours")
+	}
+}
+
+func TestLoadLocale(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "it.yaml")
+	if err := os.WriteFile(path, []byte(testLocaleYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLocale(path); err != nil {
+		t.Fatal(err)
+	}
+	dt, _ := NewWithOptions("2024-01-01", "2025-01-02", WithLocale("it"))
+	if format, _, _ := dt.DtDiff(); format != "1 anno 1 giorno" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "1 anno 1 giorno")
+	}
+
+	jsonPath := filepath.Join(dir, "pt.json")
+	json := `{"name": "pt", "units": {"year": ["ano", "anos"]}}`
+	if err := os.WriteFile(jsonPath, []byte(json), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLocale(jsonPath); err == nil || !strings.Contains(err.Error(), `unit "month"`) {
+		t.Errorf("[computed: %v] should report the missing unit", err)
+	}
+	badPath := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(badPath, []byte(`{"name": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLocale(badPath); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("[computed: %v] is not: [correct: %v]", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := ReadLocale(strings.NewReader(json), ".toml"); err == nil {
+		t.Errorf("an unsupported locale file should fail")
+	}
+	if err := RegisterLocale(&Locale{Name: "xx", Plural: "few"}); err == nil {
+		t.Errorf("an unknown plural rule should fail")
+	}
+	if err := RegisterLocale(nil); err == nil {
+		t.Errorf("a nil locale should fail")
+	}
+
+	upper := *locales["en"]
+	upper.Name = " EN-GB "
+	if err := RegisterLocale(&upper); err != nil {
+		t.Fatal(err)
+	}
+	if locale, err := LookupLocale("en-gb"); err != nil || locale.Name != "en-gb" {
+		t.Errorf("[computed: %v %v] != [correct: %v]", locale, err, "en-gb")
+	}
+}
@@ -0,0 +1,47 @@
+package dtdiff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const fixedOffset string = `^(?i:utc|gmt)?([+-])(\d{1,2})(?::?(\d{2}))?$`
+
+var fixedOffsetRegexp = regexp.MustCompile(fixedOffset)
+
+// LoadLocation return the location for an IANA time zone name such as
+// "Europe/Berlin", "UTC", "Local" or a fixed offset such as "+05:30", "-0800" or "UTC+2"
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	switch strings.ToLower(name) {
+	case "", "local":
+		return time.Local, nil
+	case "utc", "gmt", "z":
+		return time.UTC, nil
+	}
+
+	if m := fixedOffsetRegexp.FindStringSubmatch(name); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes := 0
+		if len(m[3]) > 0 {
+			minutes, _ = strconv.Atoi(m[3])
+		}
+		if hours > 14 || minutes > 59 {
+			return nil, fmt.Errorf("[LoadLocation] Invalid offset: %s", name)
+		}
+		seconds := hours*3600 + minutes*60
+		if m[1] == "-" {
+			seconds = -seconds
+		}
+		return time.FixedZone(name, seconds), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("[LoadLocation] Invalid time zone: %s", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,38 @@
+package dtdiff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadLocation(t *testing.T) {
+	allNames := []string{"Europe/Berlin", "UTC", "local", "+05:30", "-0800", "UTC+2", "GMT-03"}
+	allCorrect := []int{3600, 0, -1, 19800, -28800, 7200, -10800}
+	for i, name := range allNames {
+		loc, err := LoadLocation(name)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if allCorrect[i] == -1 {
+			continue
+		}
+		f, err := parseInLocation("2024-01-15 12:00:00", time.Now().In(loc))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		_, offset := f.Zone()
+		if offset != allCorrect[i] {
+			t.Errorf("[name: %v] [loc: %v] [computed: %v] != [correct: %v]", name, loc, offset, allCorrect[i])
+		}
+	}
+}
+
+func TestLoadLocationInvalid(t *testing.T) {
+	for _, name := range []string{"Mars/Olympus_Mons", "+25:00", "UTC+1:75"} {
+		if _, err := LoadLocation(name); err == nil {
+			t.Errorf("[name: %v] expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package dtdiff
+
+import (
+	"fmt"
+	"github.com/golang-module/carbon/v2"
+	"strings"
+	"time"
+)
+
+// Option configures the Add, Sub, recurrence and until functions as well as NewWithOptions
+type Option func(*options)
+
+// options holds the settings shared by all calculations
+type options struct {
+	parseLoc  *time.Location
+	outputLoc *time.Location
+	calendar  *Calendar
+	// nil means only use a calendar breakdown for dates without a time
+	calendarDiff *bool
+	// only one of these is set; when both are empty, results use carbon's ToString
+	layout   string
+	strftime string
+	// sum the amounts of a unit given more than once in a period
+	repeatedUnits bool
+	// Go layouts tried before the built-in parsers, set by WithLayouts
+	inputLayouts []string
+	// the only units used by DtDiff, set by WithUnits and narrowed by WithMaxUnit and WithMinUnit
+	units   map[string]bool
+	maxUnit string
+	minUnit string
+	// the current time, defaults to the system time
+	clock Clock
+	// set by WithLocale; nil means English
+	locale *Locale
+	// the output style of NewWithOptions
+	brief     bool
+	iso8601   bool
+	precision int
+	truncate  bool
+	// set by an option given an invalid value
+	err error
+}
+
+// newOptions apply all opts on top of the default settings
+// and return the first error found in any of them
+// the clock is read once here, so "now" is the same instant for the whole operation
+func newOptions(opts []Option) (options, error) {
+	o := applyOptions(opts)
+	return o.pinClock(), o.err
+}
+
+// applyOptions apply all opts on top of the default settings without reading the clock
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.err == nil {
+		o.err = o.limitUnits()
+	}
+	return o
+}
+
+// ValidateOptions return the first error found in any of opts, such as an unknown unit given to WithUnits
+// the options are only applied, nothing is parsed and the clock is not read
+func ValidateOptions(opts ...Option) error {
+	return applyOptions(opts).err
+}
+
+// pinClock return a copy of o whose clock always returns the current time
+func (o options) pinClock() options {
+	o.clock = fixedClock(o.now())
+	return o
+}
+
+// WithLocation interpret dates in loc and also return results in loc
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.parseLoc = loc
+		o.outputLoc = loc
+	}
+}
+
+// WithParseLocation interpret dates that do not include
+// an explicit offset or zone in loc instead of the local time zone
+func WithParseLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.parseLoc = loc
+	}
+}
+
+// WithOutputLocation convert all results to loc before formatting them
+func WithOutputLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.outputLoc = loc
+	}
+}
+
+// WithRepeatedUnits allow a unit to be given more than once in a period, such as "1m30s5m",
+// the amounts of each unit are summed; without this option, a repeated unit is an error
+func WithRepeatedUnits() Option {
+	return func(o *options) {
+		o.repeatedUnits = true
+	}
+}
+
+// WithWeekend set the days skipped by business day periods, defaults to Saturday and Sunday
+// this replaces the weekend of a Calendar given with WithCalendar but keeps its holidays
+func WithWeekend(days ...time.Weekday) Option {
+	return func(o *options) {
+		if !hasBusinessDay(days) {
+			o.err = weekendError("WithWeekend", days)
+			return
+		}
+		o.calendar = o.calendar.withWeekend(days)
+	}
+}
+
+// WithCalendar skip the weekends and holidays of cal in business day periods
+func WithCalendar(cal *Calendar) Option {
+	return func(o *options) {
+		o.calendar = cal
+	}
+}
+
+// WithCalendarDiff toggle walking the actual calendar between start and end, the same as SetCalendarDiff
+// without this option, the calendar is only walked when both start and end are dates without a time
+func WithCalendarDiff(calendarDiff bool) Option {
+	return func(o *options) {
+		o.calendarDiff = &calendarDiff
+	}
+}
+
+// WithBrief return durations such as "1h2m3s" from NewWithOptions, the same as SetBrief
+func WithBrief() Option {
+	return func(o *options) {
+		o.brief = true
+	}
+}
+
+// WithISO8601 return durations such as "PT1H2M3S" from NewWithOptions, the same as SetISO8601
+func WithISO8601() Option {
+	return func(o *options) {
+		o.iso8601 = true
+	}
+}
+
+// WithUnits only use these units in the result of DtDiff, such as "days", "hours" or "h";
+// the whole difference is redistributed into them, so "2 weeks 3 days" becomes "17 days"
+// when weeks are not included, and anything smaller than the smallest unit is dropped
+func WithUnits(units ...string) Option {
+	return func(o *options) {
+		o.units = make(map[string]bool)
+		for _, name := range units {
+			unit, err := breakdownUnit(name, "WithUnits")
+			if err != nil {
+				o.err = err
+				return
+			}
+			o.units[unit] = true
+		}
+	}
+}
+
+// WithMaxUnit only use "unit" and smaller units in the result of DtDiff, such as "days" for "17 days 2 hours"
+// instead of "2 weeks 3 days 2 hours"; units smaller than a second are not used unless WithMinUnit or WithUnits asks for them
+func WithMaxUnit(unit string) Option {
+	return func(o *options) {
+		u, err := breakdownUnit(unit, "WithMaxUnit")
+		if err != nil {
+			o.err = err
+			return
+		}
+		o.maxUnit = u
+	}
+}
+
+// WithMinUnit only use "unit" and larger units in the result of DtDiff, such as "hours" for "17 days 2 hours"
+// instead of "17 days 2 hours 30 minutes"; anything smaller is dropped
+func WithMinUnit(unit string) Option {
+	return func(o *options) {
+		u, err := breakdownUnit(unit, "WithMinUnit")
+		if err != nil {
+			o.err = err
+			return
+		}
+		o.minUnit = u
+	}
+}
+
+// breakdownUnit return the long singular form of one of the breakdownUnits, such as "day" for "days" or "D"
+// "caller" names the option in the error
+func breakdownUnit(name, caller string) (string, error) {
+	unit, ok := lookupUnit(strings.TrimSpace(name))
+	if !ok || unit == businessDay {
+		return "", fmt.Errorf("[%s] Invalid unit: %s; valid units are: %s", caller, name, strings.Join(breakdownUnits, ", "))
+	}
+	return unit, nil
+}
+
+// limitUnits narrow the units given with WithUnits, or every unit from years down to seconds,
+// to those between the units given with WithMaxUnit and WithMinUnit
+func (o *options) limitUnits() error {
+	if len(o.maxUnit) == 0 && len(o.minUnit) == 0 {
+		return nil
+	}
+	largest, smallest := 0, unitIndex("second")
+	if len(o.maxUnit) > 0 {
+		largest = unitIndex(o.maxUnit)
+	}
+	if len(o.minUnit) > 0 {
+		smallest = unitIndex(o.minUnit)
+	} else if len(o.units) > 0 {
+		smallest = len(breakdownUnits) - 1
+	}
+	if largest > smallest {
+		return fmt.Errorf("[WithMaxUnit] Invalid unit range: %s is smaller than %s", o.maxUnit, breakdownUnits[smallest])
+	}
+
+	units := make(map[string]bool)
+	for _, unit := range breakdownUnits[largest : smallest+1] {
+		if len(o.units) == 0 || o.units[unit] {
+			units[unit] = true
+		}
+	}
+	if len(units) == 0 {
+		return fmt.Errorf("[WithUnits] Invalid unit range: none of the units are from %s to %s", breakdownUnits[largest], breakdownUnits[smallest])
+	}
+	o.units = units
+	return nil
+}
+
+// unitIndex return the position of "unit" within breakdownUnits
+func unitIndex(unit string) int {
+	for i, name := range breakdownUnits {
+		if name == unit {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseLocation return the location used for parsing, defaults to time.Local
+func (o options) parseLocation() *time.Location {
+	if o.parseLoc == nil {
+		return time.Local
+	}
+	return o.parseLoc
+}
+
+// now return the current time of the clock given with WithClock in the parse location,
+// or the system time to the second; relative dates such as "tomorrow" are based on this time
+func (o options) now() time.Time {
+	if o.clock != nil {
+		return o.clock.Now().In(o.parseLocation())
+	}
+	return time.Now().In(o.parseLocation()).Truncate(time.Second)
+}
+
+// businessCalendar return the calendar used for business days, defaults to a Saturday and Sunday weekend
+func (o options) businessCalendar() *Calendar {
+	if o.calendar == nil {
+		return NewCalendar()
+	}
+	return o.calendar
+}
+
+// outputTime convert t to the output location, if one was given
+func (o options) outputTime(t time.Time) time.Time {
+	if o.outputLoc != nil {
+		return t.In(o.outputLoc)
+	}
+	return t
+}
+
+// format convert "to" to the output location, if one was given, and return it as
+// a string using the layout given with WithLayout or WithStrftime
+func (o options) format(to carbon.Carbon) string {
+	if o.outputLoc != nil {
+		to = to.SetLocation(o.outputLoc)
+	}
+	if len(o.layout) > 0 {
+		return formatLayout(to.StdTime(), o.layout)
+	}
+	if len(o.strftime) > 0 {
+		return strftime(to.StdTime(), o.strftime)
+	}
+	return to.ToString()
+}
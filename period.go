@@ -0,0 +1,409 @@
+package dtdiff
+
+import (
+	"fmt"
+	"github.com/golang-module/carbon/v2"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// periodLexer reads the amounts and units of a period one rune at a time
+// so that errors can report the exact character offset
+type periodLexer struct {
+	period string
+	runes  []rune
+	pos    int
+}
+
+// ValidatePeriod return an error when "period" can not be used with Add or Sub
+// the error includes the character offset of the first problem, such as:
+// [ParsePeriod] Invalid period "1h2x" at offset 3: unknown unit "x"
+func ValidatePeriod(period string, opts ...Option) error {
+	o, err := newOptions(opts)
+	if err != nil {
+		return err
+	}
+	_, err = parsePeriod(period, o.repeatedUnits)
+	return err
+}
+
+// Period is a parsed period with one field per unit, as returned by ParsePeriod
+// amounts may be negative or have a decimal portion, such as Hours: 1.5;
+// AddPeriod and its variants apply the units from the largest to the smallest
+type Period struct {
+	Years        float64
+	Months       float64
+	Weeks        float64
+	Days         float64
+	BusinessDays float64
+	Hours        float64
+	Minutes      float64
+	Seconds      float64
+	Milliseconds float64
+	Microseconds float64
+	Nanoseconds  float64
+}
+
+// briefNames maps the long singular form of each unit to its brief name
+var briefNames = map[string]string{
+	"year": "Y", "month": "M", "week": "W", "day": "D", businessDay: "BD",
+	"hour": "h", "minute": "m", "second": "s", "millisecond": "ms", "microsecond": "us", "nanosecond": "ns",
+}
+
+// ParsePeriod return "period", such as "1 month 2 days", "1M2D" or "P1M2D", as a Period
+// so that it can be validated once and then used with AddPeriod, SubPeriod and their variants;
+// a unit given more than once is an error unless WithRepeatedUnits is used
+func ParsePeriod(period string, opts ...Option) (Period, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Period{}, err
+	}
+	parts, err := parsePeriod(period, o.repeatedUnits)
+	if err != nil {
+		return Period{}, err
+	}
+	return periodFromParts(parts), nil
+}
+
+// periodFromParts return the sum of the parts of each unit as a Period
+func periodFromParts(parts []part) Period {
+	var p Period
+	fields := p.fields()
+	for _, pt := range parts {
+		*fields[pt.unit] += float64(pt.amount) + pt.fraction
+	}
+	return p
+}
+
+// fields return a pointer to each field of p, keyed by the long singular form of its unit
+func (p *Period) fields() map[string]*float64 {
+	return map[string]*float64{
+		"year": &p.Years, "month": &p.Months, "week": &p.Weeks, "day": &p.Days, businessDay: &p.BusinessDays,
+		"hour": &p.Hours, "minute": &p.Minutes, "second": &p.Seconds,
+		"millisecond": &p.Milliseconds, "microsecond": &p.Microseconds, "nanosecond": &p.Nanoseconds,
+	}
+}
+
+// parts return the non-zero fields of p from the largest unit to the smallest
+func (p Period) parts() []part {
+	var parts []part
+	fields := p.fields()
+	for _, unit := range unitOrder {
+		amount := *fields[unit]
+		if amount == 0 {
+			continue
+		}
+		whole := math.Trunc(amount)
+		parts = append(parts, part{amount: int64(whole), unit: unit, fraction: amount - whole})
+	}
+	return parts
+}
+
+// String return p in long format, such as "1 month -2 days 1.5 hours", or "0 seconds" when p is zero
+func (p Period) String() string {
+	return formatParts(p.parts(), false)
+}
+
+// Brief return p in brief format, such as "1M-2D1.5h", or "0s" when p is zero
+func (p Period) Brief() string {
+	var b strings.Builder
+	for _, pt := range p.parts() {
+		b.WriteString(pt.formatAmount() + briefNames[pt.unit])
+	}
+	if b.Len() == 0 {
+		return "0s"
+	}
+	return b.String()
+}
+
+// Add return the sum of p and q, unit by unit; no unit is converted into another
+func (p Period) Add(q Period) Period {
+	other := q.fields()
+	for unit, amount := range p.fields() {
+		*amount += *other[unit]
+	}
+	return p
+}
+
+// Negate return p with the sign of every amount reversed
+func (p Period) Negate() Period {
+	for _, amount := range p.fields() {
+		*amount = -*amount
+	}
+	return p
+}
+
+// IsZero return true when every amount of p is zero
+func (p Period) IsZero() bool {
+	return p == Period{}
+}
+
+// AddPeriod is similar to Add, but takes a Period which is not parsed again
+func AddPeriod(from string, period Period, opts ...Option) (string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	return calculateParts(from, period.String(), period.parts(), 0, o)
+}
+
+// SubPeriod is similar to Sub, but takes a Period which is not parsed again
+func SubPeriod(from string, period Period, opts ...Option) (string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	return calculateParts(from, period.String(), period.parts(), 1, o)
+}
+
+// AddPeriodWithRecurrence is similar to AddWithRecurrence, but takes a Period
+func AddPeriodWithRecurrence(from string, period Period, recurrence int, opts ...Option) ([]string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return calculatePartsWithRecurrence(from, period.String(), period.parts(), 0, recurrence, o)
+}
+
+// SubPeriodWithRecurrence is similar to SubWithRecurrence, but takes a Period
+func SubPeriodWithRecurrence(from string, period Period, recurrence int, opts ...Option) ([]string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return calculatePartsWithRecurrence(from, period.String(), period.parts(), 1, recurrence, o)
+}
+
+// AddPeriodUntil is similar to AddUntil, but takes a Period
+func AddPeriodUntil(from, until string, period Period, opts ...Option) ([]string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return calculatePartsUntil(from, until, period.String(), period.parts(), 0, o)
+}
+
+// SubPeriodUntil is similar to SubUntil, but takes a Period
+func SubPeriodUntil(from, until string, period Period, opts ...Option) ([]string, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return calculatePartsUntil(from, until, period.String(), period.parts(), 1, o)
+}
+
+// parsePeriod return each signed duration found in "period", in the order given
+// the units are in their long singular form, such as "day" or "businessday"
+// brief units (Y M W D BD h m s ms us µs ns) are case-sensitive and long units are not;
+// whitespace is allowed anywhere between amounts, signs and units; amounts may be decimals such as "1.5h"
+// a unit given more than once is an error unless "repeated" is true, then the amounts are summed
+func parsePeriod(period string, repeated bool) ([]part, error) {
+	l := periodLexer{period: period, runes: []rune(period)}
+	var parts []part
+	seen := make(map[string]int)
+
+	l.skipSpace()
+	if l.done() {
+		return nil, l.errorf(0, "empty period")
+	}
+	if l.isISO8601() {
+		return l.iso8601()
+	}
+	for !l.done() {
+		amount, fraction, err := l.amount()
+		if err != nil {
+			return nil, err
+		}
+		l.skipSpace()
+		unitStart := l.pos
+		unit, err := l.unit()
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := seen[unit]; ok {
+			if !repeated {
+				return nil, l.kindErrorf(ErrDuplicateUnit, unitStart, "duplicate unit %q, repeated units are only summed when enabled", string(l.runes[unitStart:l.pos]))
+			}
+			sum := parts[i]
+			parts[i] = part{amount: sum.amount + amount, unit: unit, fraction: sum.fraction + fraction}.normalize()
+		} else {
+			seen[unit] = len(parts)
+			parts = append(parts, part{amount: amount, unit: unit, fraction: fraction})
+		}
+		l.skipSpace()
+	}
+	return parts, nil
+}
+
+// done return true when every rune has been read
+func (l *periodLexer) done() bool {
+	return l.pos >= len(l.runes)
+}
+
+// skipSpace advance past any whitespace
+func (l *periodLexer) skipSpace() {
+	for !l.done() && unicode.IsSpace(l.runes[l.pos]) {
+		l.pos++
+	}
+}
+
+// errorf return an ErrInvalidPeriod for the character at "offset"
+func (l *periodLexer) errorf(offset int, format string, args ...interface{}) error {
+	return l.kindErrorf(ErrInvalidPeriod, offset, format, args...)
+}
+
+// kindErrorf return an InputError of "kind" for the character at "offset"
+func (l *periodLexer) kindErrorf(kind error, offset int, format string, args ...interface{}) error {
+	message := fmt.Sprintf("[ParsePeriod] Invalid period %q at offset %d: %s", l.period, offset, fmt.Sprintf(format, args...))
+	return periodError(kind, l.period, offset, message)
+}
+
+// amount read an optional sign followed by a number, which may have a decimal portion such as "1.5" or ".5"
+// the whole number and the decimal portion are returned separately, both with the sign applied
+func (l *periodLexer) amount() (int64, float64, error) {
+	start := l.pos
+	sign := int64(1)
+	if r := l.runes[l.pos]; r == '+' || r == '-' {
+		if r == '-' {
+			sign = -1
+		}
+		l.pos++
+		l.skipSpace()
+	}
+	digitsStart := l.pos
+	whole := l.digits()
+	var fraction float64
+	if !l.done() && l.runes[l.pos] == '.' {
+		point := l.pos
+		l.pos++
+		decimals := l.digits()
+		if len(decimals) == 0 {
+			return 0, 0, l.errorf(point, "expected a digit after the decimal point")
+		}
+		fraction, _ = strconv.ParseFloat("0."+decimals, 64)
+	} else if len(whole) == 0 {
+		if l.done() {
+			return 0, 0, l.errorf(l.pos, "expected a number after %q", string(l.runes[start:l.pos]))
+		}
+		return 0, 0, l.errorf(l.pos, "expected a number, found %q", string(l.runes[l.pos]))
+	}
+
+	var n int64
+	if len(whole) > 0 {
+		var err error
+		n, err = strconv.ParseInt(whole, 10, 64)
+		if err != nil {
+			return 0, 0, l.kindErrorf(ErrRangeOverflow, digitsStart, "amount out of range")
+		}
+	}
+	return sign * n, float64(sign) * fraction, nil
+}
+
+// digits read a run of decimal digits
+func (l *periodLexer) digits() string {
+	start := l.pos
+	for !l.done() && l.runes[l.pos] >= '0' && l.runes[l.pos] <= '9' {
+		l.pos++
+	}
+	return string(l.runes[start:l.pos])
+}
+
+// unit read a brief or long unit name, including "business day" which contains a space
+func (l *periodLexer) unit() (string, error) {
+	start := l.pos
+	word := l.word()
+	if len(word) == 0 {
+		if l.done() {
+			return "", l.errorf(l.pos, "expected a unit after the amount")
+		}
+		return "", l.errorf(l.pos, "expected a unit, found %q", string(l.runes[l.pos]))
+	}
+	if strings.EqualFold(word, "business") {
+		end := l.pos
+		l.skipSpace()
+		if next := strings.ToLower(l.word()); next == "day" || next == "days" {
+			return businessDay, nil
+		}
+		l.pos = end
+	}
+	unit, ok := lookupUnit(word)
+	if !ok {
+		return "", l.errorf(start, "unknown unit %q", word)
+	}
+	return unit, nil
+}
+
+// word read a run of letters
+func (l *periodLexer) word() string {
+	start := l.pos
+	for !l.done() && unicode.IsLetter(l.runes[l.pos]) {
+		l.pos++
+	}
+	return string(l.runes[start:l.pos])
+}
+
+// lookupUnit return the long singular form of a brief unit, such as "ms",
+// or of a long unit in any case, such as "Days"
+func lookupUnit(word string) (string, bool) {
+	if word == "BD" {
+		return businessDay, true
+	}
+	if unit, ok := briefUnits[word]; ok {
+		return unit, true
+	}
+	unit := removeTrailingS(strings.ToLower(word))
+	if _, ok := carbonFuncs[unit]; ok || unit == businessDay {
+		return unit, true
+	}
+	return "", false
+}
+
+// applyFraction Add (direction==0) or Sub (direction==1) the decimal portion of an amount,
+// where 0 < fraction < 1; for units below a week the fraction is converted into nanoseconds
+// using the fixed length of the unit; for weeks and longer it is converted using the actual
+// length of the next unit at that point, so 0.5 months from January 31 is half of 31 days;
+// a business day is 24 hours and any weekend or holiday crossed along the way is skipped
+func applyFraction(to carbon.Carbon, unit string, fraction float64, direction int, o options) (carbon.Carbon, error) {
+	nanosecond := carbonFuncs["nanosecond"].([2]interface{})[direction].(func(carbon.Carbon, int) carbon.Carbon)
+	if unit == businessDay {
+		return addBusinessFraction(to, fraction, direction, o)
+	}
+
+	size, fixed := fixedUnits[unit]
+	if !fixed || unit == "week" || unit == "day" {
+		next := carbonFuncs[unit].([2]interface{})[direction].(func(carbon.Carbon, int) carbon.Carbon)(to, 1)
+		size = next.StdTime().Sub(to.StdTime())
+		if size < 0 {
+			size = -size
+		}
+	}
+	return nanosecond(to, int(math.Round(fraction*float64(size)))), nil
+}
+
+// addBusinessFraction move "to" by a fraction of 24 hours; when this crosses midnight
+// onto a weekend or holiday, the following business day is used with the same time of day
+func addBusinessFraction(to carbon.Carbon, fraction float64, direction int, o options) (carbon.Carbon, error) {
+	cal := o.businessCalendar()
+	if !hasBusinessDay(cal.Weekend) {
+		return to, weekendError("addBusinessDays", cal.Weekend)
+	}
+	step := 1
+	if direction == 1 {
+		step = -1
+	}
+	start := to.StdTime()
+	t := start.Add(time.Duration(step) * time.Duration(math.Round(fraction*float64(24*time.Hour))))
+	for !sameDate(t, start) && !cal.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, step)
+	}
+	return carbon.CreateFromStdTime(t), nil
+}
+
+// sameDate return true when a and b are on the same calendar day
+func sameDate(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
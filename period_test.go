@@ -0,0 +1,184 @@
+package dtdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func testParsePeriod(t *testing.T, period string, repeated bool, correct string) {
+	parts, err := parsePeriod(period, repeated)
+	if err != nil {
+		t.Error(err)
+	}
+	computed := formatParts(parts, false)
+	if computed != correct {
+		t.Errorf("[period: %v] [computed: %v] != [correct: %v]", period, computed, correct)
+	}
+}
+
+func TestParsePeriodBriefAndLong(t *testing.T) {
+	testParsePeriod(t, "1Y2M3W4D5h6m7s8ms9us1ns", false, "1 year 2 months 3 weeks 4 days 5 hours 6 minutes 7 seconds 8 milliseconds 9 microseconds 1 nanosecond")
+	testParsePeriod(t, "10ms1m", false, "10 milliseconds 1 minute")
+	testParsePeriod(t, "1 Y 2 M\t3W  4D", false, "1 year 2 months 3 weeks 4 days")
+	testParsePeriod(t, "2µs3us", true, "5 microseconds")
+	testParsePeriod(t, "1 Year 2 MONTHS 1 day", false, "1 year 2 months 1 day")
+	testParsePeriod(t, "5 business days 2h", false, "5 business days 2 hours")
+	testParsePeriod(t, "10BD", false, "10 business days")
+	testParsePeriod(t, "1M-2D", false, "1 month -2 days")
+	testParsePeriod(t, "- 3 days + 1h", false, "-3 days 1 hour")
+}
+
+func TestParsePeriodRepeatedUnits(t *testing.T) {
+	testParsePeriod(t, "1m30s5m", true, "6 minutes 30 seconds")
+	testParsePeriod(t, "1 day 2 days -1h", true, "3 days -1 hour")
+
+	future, err := Add("2024-01-01T00:00:00Z", "1m30s5m", WithRepeatedUnits())
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(future, "2024-01-01 00:06:30") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v]", future, "2024-01-01 00:06:30")
+	}
+}
+
+func TestParsePeriodErrors(t *testing.T) {
+	tests := []struct {
+		period string
+		offset string
+	}{
+		{"1m30s5m", "at offset 6: duplicate unit"},
+		{"1h2x", "at offset 3: unknown unit \"x\""},
+		{"1.h", "at offset 1: expected a digit after the decimal point"},
+		{"1,5h", "at offset 1: expected a unit"},
+		{"h", "at offset 0: expected a number"},
+		{"1", "at offset 1: expected a unit"},
+		{"  ", "at offset 0: empty period"},
+		{"1D-", "at offset 3: expected a number"},
+		{"1 business 2h", "at offset 2: unknown unit \"business\""},
+		{"1µs2x", "at offset 4: unknown unit"},
+		{"1H", "at offset 1: unknown unit \"H\""},
+	}
+	for _, test := range tests {
+		err := ValidatePeriod(test.period)
+		if err == nil || !strings.Contains(err.Error(), test.offset) {
+			t.Errorf("[period: %v] [computed: %v] does not contain: [correct: %v]", test.period, err, test.offset)
+		}
+	}
+}
+
+func TestFractionalAmounts(t *testing.T) {
+	from := "2024-01-31T10:00:00Z"
+	testAddSubContains(t, from, "1.5h", "2024-01-31 11:30:00", "2024-01-31 08:30:00")
+	testAddSubContains(t, from, "0.5 days", "2024-01-31 22:00:00", "2024-01-30 22:00:00")
+	testAddSubContains(t, from, "2.25D", "2024-02-02 16:00:00", "2024-01-29 04:00:00")
+	testAddSubContains(t, from, ".5h", "2024-01-31 10:30:00", "2024-01-31 09:30:00")
+	testAddSubContains(t, from, "1.5ms", "2024-01-31 10:00:00.0015", "2024-01-31 09:59:59.9985")
+	// -1.5 days goes backward for Add and forward for Sub
+	testAddSubContains(t, from, "-1.5D", "2024-01-29 22:00:00", "2024-02-01 22:00:00")
+	testAddSubContains(t, from, "1M-0.5D", "2024-03-01 22:00:00", "2023-12-31 22:00:00")
+}
+
+func TestFractionalCalendarUnits(t *testing.T) {
+	// one month from January 31 overflows to March 2, then half of the 31 days to April 2 is added
+	testAddSubContains(t, "2024-01-31T10:00:00Z", "1.5M", "2024-03-17 22:00:00", "2023-12-16 10:00:00")
+	// half of the 29 days of February 2024
+	testAddSubContains(t, "2024-02-01T00:00:00Z", "0.5 months", "2024-02-15 12:00:00", "2024-01-16 12:00:00")
+	// half of the 366 days from 2024-01-01 to 2025-01-01
+	testAddSubContains(t, "2024-01-01T00:00:00Z", "0.5Y", "2024-07-02 00:00:00", "2023-07-02 12:00:00")
+}
+
+func TestFractionalBusinessDays(t *testing.T) {
+	// Friday 20:00 plus 6 hours crosses into Saturday, so Monday is used with the same time of day
+	testAddSubContains(t, "2024-02-02T20:00:00Z", "0.25BD", "2024-02-05 02:00:00", "2024-02-02 14:00:00")
+	testAddSubContains(t, "2024-01-31T10:00:00Z", "1.5 business days", "2024-02-01 22:00:00", "2024-01-29 22:00:00")
+}
+
+func TestFractionalPeriodParts(t *testing.T) {
+	testParsePeriod(t, "1.5h", false, "1.5 hours")
+	testParsePeriod(t, "-0.5D", false, "-0.5 days")
+	testParsePeriod(t, "0.75h0.5h", true, "1.25 hours")
+	testParsePeriod(t, "1.5h-0.5h", true, "1 hour")
+}
+
+func TestPeriodType(t *testing.T) {
+	p, err := ParsePeriod("1 month -2 days 1.5h")
+	if err != nil {
+		t.Error(err)
+	}
+	correct := Period{Months: 1, Days: -2, Hours: 1.5}
+	if p != correct {
+		t.Errorf("[computed: %+v] != [correct: %+v]", p, correct)
+	}
+	tests := []struct {
+		computed string
+		correct  string
+	}{
+		{p.String(), "1 month -2 days 1.5 hours"},
+		{p.Brief(), "1M-2D1.5h"},
+		{p.Negate().String(), "-1 month 2 days -1.5 hours"},
+		{p.Add(Period{Days: 2, BusinessDays: 3}).String(), "1 month 3 business days 1.5 hours"},
+		{Period{}.String(), "0 seconds"},
+		{Period{}.Brief(), "0s"},
+		{Period{Weeks: 1, Nanoseconds: 1}.Brief(), "1W1ns"},
+	}
+	for _, test := range tests {
+		if test.computed != test.correct {
+			t.Errorf("[computed: %v] != [correct: %v]", test.computed, test.correct)
+		}
+	}
+	if p.IsZero() || !p.Add(p.Negate()).IsZero() || !(Period{}).IsZero() {
+		t.Errorf("[computed: %v] IsZero is incorrect", p)
+	}
+
+	// the brief format can be parsed again
+	again, err := ParsePeriod(p.Brief())
+	if err != nil || again != p {
+		t.Errorf("[computed: %+v] != [correct: %+v] %v", again, p, err)
+	}
+
+	if _, err := ParsePeriod("1h2x"); err == nil || !strings.Contains(err.Error(), "at offset 3") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v]", err, "at offset 3")
+	}
+	if p, _ := ParsePeriod("1m30s5m", WithRepeatedUnits()); p != (Period{Minutes: 6, Seconds: 30}) {
+		t.Errorf("[computed: %+v] != [correct: %+v]", p, Period{Minutes: 6, Seconds: 30})
+	}
+}
+
+func TestAddSubPeriod(t *testing.T) {
+	from := "2024-01-31T10:00:00Z"
+	period := Period{Months: 1, Days: 1, Hours: 1.5}
+	future, err := AddPeriod(from, period)
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(future, "2024-03-03 11:30:00") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v]", future, "2024-03-03 11:30:00")
+	}
+	past, err := SubPeriod(from, period)
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(past, "2023-12-30 08:30:00") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v]", past, "2023-12-30 08:30:00")
+	}
+
+	all, err := AddPeriodWithRecurrence(from, Period{Days: 1}, 2)
+	if err != nil || len(all) != 2 || !strings.Contains(all[1], "2024-02-02 10:00:00") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v] %v", all, "2024-02-02 10:00:00", err)
+	}
+	all, err = SubPeriodWithRecurrence(from, Period{Weeks: 1}, 2)
+	if err != nil || len(all) != 2 || !strings.Contains(all[1], "2024-01-17 10:00:00") {
+		t.Errorf("[computed: %v] does not contain: [correct: %v] %v", all, "2024-01-17 10:00:00", err)
+	}
+	all, err = AddPeriodUntil(from, "2024-02-02T12:00:00Z", Period{Days: 1})
+	if err != nil || len(all) != 2 {
+		t.Errorf("[computed: %v] != [correct: %v] %v", len(all), 2, err)
+	}
+	all, err = SubPeriodUntil(from, "2024-01-29T00:00:00Z", Period{Days: 1})
+	if err != nil || len(all) != 2 {
+		t.Errorf("[computed: %v] != [correct: %v] %v", len(all), 2, err)
+	}
+	if _, err = AddPeriodUntil(from, "2024-02-02T12:00:00Z", Period{}); err == nil {
+		t.Errorf("a zero period should not move toward until")
+	}
+}
@@ -0,0 +1,133 @@
+package dtdiff
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// yearLength is the length of a year in the default output of DtDiff, which does not walk the calendar
+const yearLength = 365 * 24 * time.Hour
+
+// WithPrecision only keep the "precision" most significant non-zero units in the result of DtDiff,
+// the same as SetPrecision; the remainder is rounded unless WithTruncate is also given
+func WithPrecision(precision int) Option {
+	return func(o *options) {
+		if precision < 0 {
+			o.err = fmt.Errorf("[WithPrecision] Invalid precision: %d", precision)
+			return
+		}
+		o.precision = precision
+	}
+}
+
+// WithTruncate drop the units beyond the precision instead of rounding them, the same as SetTruncate
+func WithTruncate() Option {
+	return func(o *options) {
+		o.truncate = true
+	}
+}
+
+// SetPrecision only keep the "precision" most significant non-zero units, such as 2 for "1 year 2 weeks"
+// instead of "1 year 2 weeks 3 days 4 hours"; 0 keeps every unit
+// the remainder is rounded and carried into the larger units, so 59m59.6s is 1 hour with a precision of 2
+func (dt *DtDiff) SetPrecision(precision int) {
+	dt.Precision = precision
+}
+
+// SetTruncate drop the units beyond the precision instead of rounding them, so 59m59.6s is 59m59s
+func (dt *DtDiff) SetTruncate(truncate bool) {
+	dt.Truncate = truncate
+}
+
+// splitFunc splits the difference from start to end into parts from the largest unit to the smallest,
+// along with whether end is before start
+type splitFunc func(start, end time.Time) ([]part, bool)
+
+// fixedParts split the difference into the units of durafmt: 365 day years, weeks, days and clock units
+func fixedParts(start, end time.Time) ([]part, bool) {
+	return durafmtParts(end.Sub(start))
+}
+
+// dayParts split the difference into 24 hour days and clock units, as used by ISO 8601 output
+func dayParts(start, end time.Time) ([]part, bool) {
+	diff, negative := end.Sub(start), false
+	if diff < 0 {
+		diff, negative = -diff, true
+	}
+	day := fixedUnits["day"]
+	return append([]part{{amount: int64(diff / day), unit: "day"}}, clockParts(diff%day)...), negative
+}
+
+// limitParts split the difference from start to end and keep the "precision" most significant non-zero parts
+// unless "truncate" is true, the remainder is rounded half up: when end is at least halfway to the next amount
+// of the smallest kept unit, that amount is used instead and the difference is split again, which carries into
+// the larger units; "fixed" is true when split uses fixed length years, weeks and days instead of the calendar
+func limitParts(start, end time.Time, precision int, truncate bool, split splitFunc, fixed bool) ([]part, bool) {
+	parts, negative := split(start, end)
+	last, kept := -1, 0
+	for i, p := range parts {
+		if p.amount != 0 && kept < precision {
+			last, kept = i, kept+1
+		}
+	}
+	if precision == 0 || last == -1 {
+		return parts, negative
+	}
+	for i := last + 1; i < len(parts); i++ {
+		parts[i].amount = 0
+	}
+	if truncate {
+		return parts, negative
+	}
+
+	sign := 1
+	if negative {
+		sign = -1
+	}
+	low := advance(start, parts, sign, fixed)
+	next := append([]part{}, parts...)
+	next[last].amount++
+	high := advance(start, next, sign, fixed)
+	if absDuration(end.Sub(low))*2 < absDuration(high.Sub(low)) {
+		return parts, negative
+	}
+	return limitParts(start, high, precision, true, split, fixed)
+}
+
+// advance return t moved by each of the parts in the direction of "sign", in the same order as the breakdowns
+// years, months, weeks and days walk the calendar unless "fixed" is true, then a year is 365 days
+func advance(t time.Time, parts []part, sign int, fixed bool) time.Time {
+	for _, p := range parts {
+		n := int64(sign) * p.amount
+		size, ok := fixedUnits[p.unit]
+		switch {
+		case p.unit == "year" && fixed:
+			t = addFixed(t, n, yearLength)
+		case ok && (fixed || (p.unit != "week" && p.unit != "day")):
+			t = addFixed(t, n, size)
+		default:
+			t = addCalendarUnit(t, p.unit, int(n))
+		}
+	}
+	return t
+}
+
+// addFixed return t moved by n units of "size", which can be more than a time.Duration holds
+func addFixed(t time.Time, n int64, size time.Duration) time.Time {
+	limit := int64(math.MaxInt64 / size)
+	for n != 0 {
+		step := max(min(n, limit), -limit)
+		t = t.Add(time.Duration(step) * size)
+		n -= step
+	}
+	return t
+}
+
+// absDuration return the absolute value of d
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,72 @@
+package dtdiff
+
+import (
+	"testing"
+)
+
+func TestPrecision(t *testing.T) {
+	tests := []struct {
+		start     string
+		end       string
+		precision int
+		truncate  bool
+		brief     bool
+		correct   string
+	}{
+		{"2024-01-01 00:00:00", "2025-01-17 04:05:06.789", 2, false, false, "1 year 2 weeks"},
+		{"2024-01-01 00:00:00", "2025-01-17 04:05:06.789", 3, false, true, "1Y2W3D"},
+		{"2024-01-01 00:00:00", "2025-01-17 14:05:06.789", 3, false, false, "1 year 2 weeks 4 days"},
+		{"2024-01-01 00:00:00", "2025-01-17 14:05:06.789", 3, true, false, "1 year 2 weeks 3 days"},
+		{"2024-01-01 12:00:00", "2024-01-01 12:59:59.6", 2, false, false, "1 hour"},
+		{"2024-01-01 12:00:00", "2024-01-01 12:59:59.6", 2, true, true, "59m59s"},
+		{"2024-01-01 12:59:59.6", "2024-01-01 12:00:00", 2, false, false, "-1 hour"},
+		{"12:00:00", "13:30:00", 1, false, false, "2 hours"},
+		{"12:00:00", "13:29:59", 1, false, false, "1 hour"},
+		{"12:00:00", "12:00:00", 1, false, false, "0 seconds"},
+		{"2024-01-01", "2024-12-20", 1, false, false, "1 year"},
+		{"2024-01-01", "2024-12-20", 2, false, false, "11 months 19 days"},
+		{"2024-01-31", "2024-03-17", 1, false, false, "2 months"},
+		{"2024-01-31", "2024-03-17", 1, true, false, "1 month"},
+	}
+	for _, test := range tests {
+		opts := []Option{WithPrecision(test.precision)}
+		if test.truncate {
+			opts = append(opts, WithTruncate())
+		}
+		if test.brief {
+			opts = append(opts, WithBrief())
+		}
+		dt, err := NewWithOptions(test.start, test.end, opts...)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		format, _, err := dt.DtDiff()
+		if err != nil {
+			t.Error(err)
+		}
+		if format != test.correct {
+			t.Errorf("[%v - %v precision: %v] [computed: %v] != [correct: %v]", test.start, test.end, test.precision, format, test.correct)
+		}
+	}
+
+	dt := New("12:00:00", "13:29:31")
+	dt.SetPrecision(2)
+	dt.SetISO8601(true)
+	if format, _, _ := dt.DtDiff(); format != "PT1H30M" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "PT1H30M")
+	}
+	dt.SetTruncate(true)
+	if format, _, _ := dt.DtDiff(); format != "PT1H29M" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "PT1H29M")
+	}
+
+	dt, _ = NewWithOptions("2024-01-01", "2024-03-20", WithUnits("weeks", "days"), WithPrecision(1))
+	if format, _, _ := dt.DtDiff(); format != "11 weeks" {
+		t.Errorf("[computed: %v] != [correct: %v]", format, "11 weeks")
+	}
+
+	if _, err := NewWithOptions("12:00", "13:00", WithPrecision(-1)); err == nil {
+		t.Errorf("a negative precision should fail")
+	}
+}
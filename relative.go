@@ -0,0 +1,215 @@
+package dtdiff
+
+import (
+	"fmt"
+	"github.com/golang-module/carbon/v2"
+	"strings"
+	"time"
+)
+
+// relativeForms is included in the error returned for an unrecognized relative phrase
+const relativeForms string = `now, today, yesterday, tomorrow,
+next|last|this <weekday>, such as "next monday" or "last fri",
+next|last week|month|quarter|year,
+<period> ago, such as "3 days ago" or "2h30m ago",
+in <period>, such as "in 2 weeks" or "in 5 business days",
+start of|end of [next|last] day|week|month|quarter|year, such as "end of quarter";
+any of these can be followed by a time, such as "tomorrow 9am" or "last friday 17:00"`
+
+// relativeKeywords are the words which begin a relative phrase
+var relativeKeywords = map[string]bool{
+	"now": true, "today": true, "yesterday": true, "tomorrow": true,
+	"next": true, "last": true, "this": true, "in": true, "start": true, "end": true,
+}
+
+// clockLayouts are the accepted formats of a time following a relative phrase
+var clockLayouts = []string{"15:04", "15:04:05", "15:04:05.999999999", "3pm", "3:04pm", "3:04:05pm"}
+
+// convertRelativeDate convert a relative phrase, such as "next monday" or "3 days ago", into a time
+// based on "current"; the time of day of "current" is kept unless a time follows the phrase,
+// "start of" and "end of" use the first and last instant of the day, week, month, quarter or year
+// ok is false when "value" is not a relative phrase; weeks start on Monday
+func convertRelativeDate(value string, current time.Time, o options) (t time.Time, ok bool, err error) {
+	words := strings.Fields(value)
+	if len(words) == 0 {
+		return current, false, nil
+	}
+
+	// an optional time of day follows the phrase, so it is removed before looking for "ago"
+	clock, hasClock := parseClock(words[len(words)-1])
+	if hasClock && len(words) > 1 {
+		words = words[:len(words)-1]
+	} else {
+		hasClock = false
+	}
+	first := strings.ToLower(words[0])
+	last := strings.ToLower(words[len(words)-1])
+	if !relativeKeywords[first] && last != "ago" {
+		return current, false, nil
+	}
+
+	t, err = relativePhrase(words, current, o)
+	if err != nil {
+		return current, true, err
+	}
+	if hasClock {
+		t = time.Date(t.Year(), t.Month(), t.Day(), clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), t.Location())
+	}
+	return t, true, nil
+}
+
+// relativePhrase return the time described by "words", a relative phrase without a trailing time
+func relativePhrase(words []string, current time.Time, o options) (time.Time, error) {
+	phrase := strings.ToLower(strings.Join(words, " "))
+	unrecognized := fmt.Errorf("[convertRelativeDate] Unrecognized relative date: %s; supported forms are:\n%s", strings.Join(words, " "), relativeForms)
+
+	switch phrase {
+	case "now", "today":
+		return current, nil
+	case "yesterday":
+		return current.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return current.AddDate(0, 0, 1), nil
+	}
+
+	first := strings.ToLower(words[0])
+	switch {
+	case len(words) >= 2 && strings.ToLower(words[len(words)-1]) == "ago":
+		return relativePeriod(strings.Join(words[:len(words)-1], " "), current, 1, o, unrecognized)
+	case first == "in" && len(words) >= 2:
+		return relativePeriod(strings.Join(words[1:], " "), current, 0, o, unrecognized)
+	case (first == "next" || first == "last" || first == "this") && len(words) == 2:
+		if day, ok := parseWeekday(words[1]); ok {
+			return relativeWeekday(first, day, current), nil
+		}
+		if first == "this" {
+			return current, unrecognized
+		}
+		return shiftUnit(current, strings.ToLower(words[1]), direction(first), unrecognized)
+	case (first == "start" || first == "end") && len(words) >= 3 && strings.ToLower(words[1]) == "of":
+		unit := strings.ToLower(words[len(words)-1])
+		which := "this"
+		if len(words) == 4 {
+			which = strings.ToLower(words[2])
+			if which != "next" && which != "last" && which != "this" {
+				return current, unrecognized
+			}
+		} else if len(words) != 3 {
+			return current, unrecognized
+		}
+		// shift the start, so that "end of next month" on January 31 is the end of February
+		start, err := startOf(current, unit, unrecognized)
+		if err != nil {
+			return current, err
+		}
+		start, _ = shiftUnit(start, unit, direction(which), unrecognized)
+		if first == "start" {
+			return start, nil
+		}
+		next, _ := shiftUnit(start, unit, 1, unrecognized)
+		return next.Add(-time.Nanosecond), nil
+	}
+	return current, unrecognized
+}
+
+// relativePeriod Add (index==0) or Sub (index==1) a period such as "3 days" or "2h30m" to current
+func relativePeriod(period string, current time.Time, index int, o options, unrecognized error) (time.Time, error) {
+	parts, err := parsePeriod(period, o.repeatedUnits)
+	if err != nil {
+		return current, unrecognized
+	}
+	to, err := applyParts(carbon.CreateFromStdTime(current), period, parts, index, o)
+	if err != nil {
+		return current, err
+	}
+	return to.StdTime(), nil
+}
+
+// relativeWeekday return the closest "day" after current for "next", before current for "last",
+// or within the current Monday to Sunday week for "this"
+func relativeWeekday(which string, day time.Weekday, current time.Time) time.Time {
+	switch which {
+	case "next":
+		days := (int(day) - int(current.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return current.AddDate(0, 0, days)
+	case "last":
+		days := (int(current.Weekday()) - int(day) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return current.AddDate(0, 0, -days)
+	}
+	return current.AddDate(0, 0, mondayIndex(day)-mondayIndex(current.Weekday()))
+}
+
+// mondayIndex return 0 for Monday through 6 for Sunday
+func mondayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// direction return 1 for "next", -1 for "last" and 0 for "this"
+func direction(which string) int {
+	switch which {
+	case "next":
+		return 1
+	case "last":
+		return -1
+	}
+	return 0
+}
+
+// shiftUnit move t by n days, weeks, months, quarters or years
+func shiftUnit(t time.Time, unit string, n int, unrecognized error) (time.Time, error) {
+	switch unit {
+	case "day":
+		return t.AddDate(0, 0, n), nil
+	case "week":
+		return t.AddDate(0, 0, 7*n), nil
+	case "month":
+		return t.AddDate(0, n, 0), nil
+	case "quarter":
+		return t.AddDate(0, 3*n, 0), nil
+	case "year":
+		return t.AddDate(n, 0, 0), nil
+	}
+	return t, unrecognized
+}
+
+// startOf return the first instant of the day, week, month, quarter or year containing t
+func startOf(t time.Time, unit string, unrecognized error) (time.Time, error) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch unit {
+	case "day":
+		return day, nil
+	case "week":
+		return day.AddDate(0, 0, -mondayIndex(t.Weekday())), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()), nil
+	case "quarter":
+		month := time.Month((int(t.Month())-1)/3*3 + 1)
+		return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location()), nil
+	case "year":
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location()), nil
+	}
+	return t, unrecognized
+}
+
+// parseClock parse a time of day such as "17:00", "9am" or "noon"
+func parseClock(value string) (time.Time, bool) {
+	value = strings.ToLower(value)
+	switch value {
+	case "noon":
+		return time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC), true
+	case "midnight":
+		return time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), true
+	}
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,76 @@
+package dtdiff
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRelative(t *testing.T, value string, current time.Time, correct string) {
+	computed, ok, err := convertRelativeDate(value, current, options{})
+	if err != nil {
+		t.Error(err)
+	}
+	if !ok {
+		t.Errorf("[value: %v] was not recognized as a relative date", value)
+	}
+	if computed.Format(time.RFC3339Nano) != correct {
+		t.Errorf("[value: %v] [computed: %v] != [correct: %v]", value, computed.Format(time.RFC3339Nano), correct)
+	}
+}
+
+func TestRelativeWeekdays(t *testing.T) {
+	// a Wednesday
+	current := time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)
+	testRelative(t, "next monday", current, "2024-02-05T10:30:00Z")
+	testRelative(t, "Next Wed", current, "2024-02-07T10:30:00Z")
+	testRelative(t, "last friday 17:00", current, "2024-01-26T17:00:00Z")
+	testRelative(t, "last wednesday", current, "2024-01-24T10:30:00Z")
+	testRelative(t, "this sunday", current, "2024-02-04T10:30:00Z")
+	testRelative(t, "this monday 9am", current, "2024-01-29T09:00:00Z")
+}
+
+func TestRelativePeriods(t *testing.T) {
+	current := time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)
+	testRelative(t, "3 days ago", current, "2024-01-28T10:30:00Z")
+	testRelative(t, "2h30m ago", current, "2024-01-31T08:00:00Z")
+	testRelative(t, "3 days ago 17:00", current, "2024-01-28T17:00:00Z")
+	testRelative(t, "1 week ago 9am", current, "2024-01-24T09:00:00Z")
+	testRelative(t, "in 2 weeks", current, "2024-02-14T10:30:00Z")
+	testRelative(t, "in 2 business days", current, "2024-02-02T10:30:00Z")
+	testRelative(t, "tomorrow 9am", current, "2024-02-01T09:00:00Z")
+	testRelative(t, "yesterday noon", current, "2024-01-30T12:00:00Z")
+	testRelative(t, "next month", current, "2024-03-02T10:30:00Z")
+	testRelative(t, "last year", current, "2023-01-31T10:30:00Z")
+}
+
+func TestRelativeStartEndOf(t *testing.T) {
+	current := time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)
+	testRelative(t, "start of month", current, "2024-01-01T00:00:00Z")
+	testRelative(t, "end of quarter", current, "2024-03-31T23:59:59.999999999Z")
+	testRelative(t, "end of next month", current, "2024-02-29T23:59:59.999999999Z")
+	testRelative(t, "start of last quarter", current, "2023-10-01T00:00:00Z")
+	testRelative(t, "start of week", current, "2024-01-29T00:00:00Z")
+	testRelative(t, "end of day", current, "2024-01-31T23:59:59.999999999Z")
+	testRelative(t, "start of year 08:00", current, "2024-01-01T08:00:00Z")
+}
+
+func TestRelativeUnrecognized(t *testing.T) {
+	current := time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)
+	for _, value := range []string{"next blah", "end of decade", "in a while", "last", "start of the month"} {
+		_, ok, err := convertRelativeDate(value, current, options{})
+		if !ok || err == nil || !strings.Contains(err.Error(), "supported forms") {
+			t.Errorf("[value: %v] an error listing the supported forms was expected, got: %v", value, err)
+		}
+	}
+	for _, value := range []string{"2024-01-31", "15:04", "Jan 2 2024"} {
+		if _, ok, _ := convertRelativeDate(value, current, options{}); ok {
+			t.Errorf("[value: %v] should not be treated as a relative date", value)
+		}
+	}
+}
+
+func TestRelativeDiff(t *testing.T) {
+	testStartEnd(t, "3 days ago", "today", "3 days")
+	testStartEnd(t, "yesterday 9am", "tomorrow 9am", "2 days")
+}
@@ -0,0 +1,269 @@
+package dtdiff
+
+import (
+	"fmt"
+	"github.com/golang-module/carbon/v2"
+	"math/big"
+	"time"
+)
+
+// TimeIterator steps forward or backward from a time by a period, one step for each call to Next,
+// similar to bufio.Scanner:
+//
+//	it := dtdiff.IterateAdd(t, dtdiff.Period{Days: 1}).Until(end)
+//	for it.Next() {
+//		fmt.Println(it.Time())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// without Until the iterator never ends, so the caller decides when to stop
+type TimeIterator struct {
+	t      time.Time
+	parts  []part
+	index  int
+	o      options
+	period string
+	// set by Until
+	until     time.Time
+	untilName string
+	bounded   bool
+	done      bool
+	err       error
+}
+
+// step Add (index==0) or Sub (index==1) the parts of "period" to t once, in the location of t
+func step(t time.Time, period string, parts []part, index int, o options) (time.Time, error) {
+	to, err := applyParts(carbon.CreateFromStdTime(t), period, parts, index, o)
+	if err != nil {
+		return t, err
+	}
+	return to.StdTime(), nil
+}
+
+// stepWithRecurrence return each of the "recurrence" steps from t
+func stepWithRecurrence(t time.Time, period string, parts []part, index, recurrence int, o options) ([]time.Time, error) {
+	var all []time.Time
+	var err error
+	for i := 0; i < recurrence; i++ {
+		t, err = step(t, period, parts, index, o)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, t)
+	}
+	return all, nil
+}
+
+// formatTimes return each time in the output location and layout
+func formatTimes(all []time.Time, o options) []string {
+	var formatted []string
+	for _, t := range all {
+		formatted = append(formatted, o.format(carbon.CreateFromStdTime(t)))
+	}
+	return formatted
+}
+
+// outputTimes return each time in the output location, if one was given
+func outputTimes(all []time.Time, o options) []time.Time {
+	for i, t := range all {
+		all[i] = o.outputTime(t)
+	}
+	return all
+}
+
+// newTimeIterator return an iterator which adds (index==0) or subtracts (index==1) period from t
+func newTimeIterator(t time.Time, period Period, index int, opts []Option) *TimeIterator {
+	o, err := newOptions(opts)
+	return &TimeIterator{t: t, parts: period.parts(), index: index, o: o, period: period.String(), err: err}
+}
+
+// IterateAdd return an iterator of the future times at intervals of length "period" after t
+func IterateAdd(t time.Time, period Period, opts ...Option) *TimeIterator {
+	return newTimeIterator(t, period, 0, opts)
+}
+
+// IterateSub return an iterator of the past times at intervals of length "period" before t
+func IterateSub(t time.Time, period Period, opts ...Option) *TimeIterator {
+	return newTimeIterator(t, period, 1, opts)
+}
+
+// Until stop the iterator once "until" is exceeded, the same as AddUntil and SubUntil
+// Next then fails when a step does not move toward "until", such as for "1 day -24 hours"
+func (it *TimeIterator) Until(until time.Time) *TimeIterator {
+	it.until = until
+	it.untilName = until.Format(time.RFC3339Nano)
+	it.bounded = true
+	return it
+}
+
+// Next advance the iterator by one step and return false when it is finished or failed
+func (it *TimeIterator) Next() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+	previous := it.t
+	next, err := step(it.t, it.period, it.parts, it.index, it.o)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if !it.bounded {
+		it.t = next
+		return true
+	}
+
+	// a signed period such as "1 day -1 day" could otherwise loop forever
+	if !movesToward(previous, next, it.index) {
+		message := fmt.Sprintf("[calculateUntil] Invalid period: %s; it does not move toward %s", it.period, it.untilName)
+		it.err = periodError(ErrInvalidPeriod, it.period, -1, message)
+		return false
+	}
+	if (it.index == 0 && next.After(it.until)) || (it.index == 1 && next.Before(it.until)) {
+		it.done = true
+		return false
+	}
+	it.t = next
+	return true
+}
+
+// Time return the time reached by the last call to Next, in the output location when one was given
+func (it *TimeIterator) Time() time.Time {
+	return it.o.outputTime(it.t)
+}
+
+// Err return the first error found by Next, if any
+func (it *TimeIterator) Err() error {
+	return it.err
+}
+
+// all return every remaining time of a bounded iterator, in the location of the starting time
+func (it *TimeIterator) all() ([]time.Time, error) {
+	var all []time.Time
+	for it.Next() {
+		all = append(all, it.t)
+	}
+	return all, it.Err()
+}
+
+// AddTime return t plus "period", in the output location when one was given
+// unlike Add, the result keeps every digit of precision and the location of t
+func AddTime(t time.Time, period Period, opts ...Option) (time.Time, error) {
+	return stepTime(t, period, 0, opts)
+}
+
+// SubTime return t minus "period", in the output location when one was given
+func SubTime(t time.Time, period Period, opts ...Option) (time.Time, error) {
+	return stepTime(t, period, 1, opts)
+}
+
+// stepTime Add (index==0) or Sub (index==1) period to t once
+func stepTime(t time.Time, period Period, index int, opts []Option) (time.Time, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return t, err
+	}
+	to, err := step(t, period.String(), period.parts(), index, o)
+	if err != nil {
+		return t, err
+	}
+	return o.outputTime(to), nil
+}
+
+// AddTimeWithRecurrence similar to AddTime, but returns a slice
+// of multiple future times at intervals of length "period"
+func AddTimeWithRecurrence(t time.Time, period Period, recurrence int, opts ...Option) ([]time.Time, error) {
+	return stepTimeWithRecurrence(t, period, 0, recurrence, opts)
+}
+
+// SubTimeWithRecurrence similar to SubTime, but returns a slice
+// of multiple past times at intervals of length "period"
+func SubTimeWithRecurrence(t time.Time, period Period, recurrence int, opts ...Option) ([]time.Time, error) {
+	return stepTimeWithRecurrence(t, period, 1, recurrence, opts)
+}
+
+// stepTimeWithRecurrence Add (index==0) or Sub (index==1) period to t "recurrence" times
+func stepTimeWithRecurrence(t time.Time, period Period, index, recurrence int, opts []Option) ([]time.Time, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	all, err := stepWithRecurrence(t, period.String(), period.parts(), index, recurrence, o)
+	if err != nil {
+		return nil, err
+	}
+	return outputTimes(all, o), nil
+}
+
+// AddTimeUntil similar to AddTime, but returns a slice
+// of multiple future times until "until" is exceeded
+func AddTimeUntil(t, until time.Time, period Period, opts ...Option) ([]time.Time, error) {
+	return stepTimeUntil(t, until, period, 0, opts)
+}
+
+// SubTimeUntil similar to SubTime, but returns a slice
+// of multiple past times until "until" is exceeded
+func SubTimeUntil(t, until time.Time, period Period, opts ...Option) ([]time.Time, error) {
+	return stepTimeUntil(t, until, period, 1, opts)
+}
+
+// stepTimeUntil Add (index==0) or Sub (index==1) period to t until "until" is exceeded
+func stepTimeUntil(t, until time.Time, period Period, index int, opts []Option) ([]time.Time, error) {
+	it := newTimeIterator(t, period, index, opts).Until(until)
+	all, err := it.all()
+	if err != nil {
+		return nil, err
+	}
+	return outputTimes(all, it.o), nil
+}
+
+// DiffTimes return the period from a to b in weeks, days and clock units, such as Period{Weeks: 1, Hours: 2},
+// in years, months and days when WithCalendarDiff(true) is given, or in the units given with WithUnits;
+// every amount is negative when b is before a; spans of more than 292 years are supported, but
+// ErrRangeOverflow is returned when the largest of the units is too small, such as nanoseconds for 300 years
+func DiffTimes(a, b time.Time, opts ...Option) (Period, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Period{}, err
+	}
+	if len(o.units) > 0 {
+		if unit := unitsOverflow(a, b, o.units); len(unit) > 0 {
+			message := fmt.Sprintf("[DiffTimes] Range overflow: the difference from %s to %s is too large to count in %ss; allow a larger unit", a.Format(time.RFC3339Nano), b.Format(time.RFC3339Nano), unit)
+			return Period{}, &InputError{Kind: ErrRangeOverflow, Input: b.Format(time.RFC3339Nano), Field: FieldEnd, Position: -1, message: message}
+		}
+		parts, negative := unitBreakdown(a, b, o.units)
+		if negative {
+			parts = scaleParts(parts, -1)
+		}
+		return periodFromParts(parts), nil
+	}
+	return periodFromParts(differenceParts(a, b, o.calendarDiff != nil && *o.calendarDiff)), nil
+}
+
+// exactParts split the difference from start to end into weeks, days and clock units
+// unlike a time.Duration, this is not limited to about 292 years
+func exactParts(start, end time.Time) ([]part, bool) {
+	nanos := exactDiff(start, end)
+	negative := nanos.Sign() < 0
+	weeks, rest := new(big.Int).QuoRem(nanos.Abs(nanos), big.NewInt(int64(fixedUnits["week"])), new(big.Int))
+	parts := durationParts(time.Duration(rest.Int64()))
+	parts[0].amount = weeks.Int64()
+	return parts, negative
+}
+
+// differenceParts return the signed parts from start to end, walking the calendar when "useCalendar" is true
+// and otherwise using weeks, days and clock units; units which are zero are dropped
+func differenceParts(start, end time.Time, useCalendar bool) []part {
+	var parts []part
+	var negative bool
+	if useCalendar {
+		parts, negative = calendarBreakdown(start, end)
+	} else {
+		parts, negative = exactParts(start, end)
+	}
+	if negative {
+		parts = scaleParts(parts, -1)
+	}
+	return combineParts(parts)
+}
@@ -0,0 +1,159 @@
+package dtdiff
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAddSubTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, 1, 31, 10, 0, 0, 123456789, berlin)
+
+	future, err := AddTime(from, Period{Months: 1, Hours: 1.5})
+	if err != nil {
+		t.Error(err)
+	}
+	correct := time.Date(2024, 3, 2, 11, 30, 0, 123456789, berlin)
+	if !future.Equal(correct) || future.Location() != berlin {
+		t.Errorf("[computed: %v] != [correct: %v]", future, correct)
+	}
+
+	past, err := SubTime(from, Period{Days: 1, Nanoseconds: 123456789})
+	if err != nil {
+		t.Error(err)
+	}
+	correct = time.Date(2024, 1, 30, 10, 0, 0, 0, berlin)
+	if !past.Equal(correct) {
+		t.Errorf("[computed: %v] != [correct: %v]", past, correct)
+	}
+
+	utc, _ := LoadLocation("UTC")
+	future, _ = AddTime(from, Period{Hours: 1}, WithOutputLocation(utc))
+	if future.Location() != utc || future.Hour() != 10 {
+		t.Errorf("[computed: %v] != [correct: %v]", future, "2024-01-31 10:00:00.123456789 +0000 UTC")
+	}
+}
+
+func TestTimeRecurrence(t *testing.T) {
+	from := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	all, err := AddTimeWithRecurrence(from, Period{Months: 1}, 3)
+	if err != nil {
+		t.Error(err)
+	}
+	correct := []time.Time{
+		time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+	}
+	testTimes(t, all, correct)
+
+	all, err = SubTimeWithRecurrence(from, Period{Weeks: 1}, 2)
+	if err != nil {
+		t.Error(err)
+	}
+	testTimes(t, all, []time.Time{time.Date(2024, 1, 24, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC)})
+
+	all, err = AddTimeUntil(from, time.Date(2024, 2, 2, 12, 0, 0, 0, time.UTC), Period{Days: 1})
+	if err != nil {
+		t.Error(err)
+	}
+	testTimes(t, all, []time.Time{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)})
+
+	all, err = SubTimeUntil(from, time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC), Period{Hours: 12})
+	if err != nil {
+		t.Error(err)
+	}
+	testTimes(t, all, []time.Time{time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC), time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)})
+
+	if _, err = AddTimeUntil(from, time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC), Period{Days: 1, Hours: -24}); err == nil {
+		t.Errorf("AddTimeUntil should fail when the period does not move toward until")
+	}
+}
+
+func testTimes(t *testing.T, computed, correct []time.Time) {
+	if len(computed) != len(correct) {
+		t.Errorf("[computed: %v] != [correct: %v]", computed, correct)
+		return
+	}
+	for i := range computed {
+		if !computed[i].Equal(correct[i]) {
+			t.Errorf("[computed: %v] != [correct: %v]", computed[i], correct[i])
+		}
+	}
+}
+
+func TestTimeIterator(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	it := IterateAdd(from, Period{Days: 1})
+	var all []time.Time
+	for it.Next() && len(all) < 3 {
+		all = append(all, it.Time())
+	}
+	if it.Err() != nil {
+		t.Error(it.Err())
+	}
+	testTimes(t, all, []time.Time{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)})
+
+	it = IterateSub(from, Period{Months: 1}).Until(time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC))
+	all = nil
+	for it.Next() {
+		all = append(all, it.Time())
+	}
+	if it.Err() != nil {
+		t.Error(it.Err())
+	}
+	testTimes(t, all, []time.Time{time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)})
+
+	it = IterateAdd(from, Period{}).Until(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	if it.Next() || it.Err() == nil {
+		t.Errorf("a zero period should not move toward until")
+	}
+}
+
+func TestDiffTimes(t *testing.T) {
+	a := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(2024, 1, 10, 2, 30, 0, 5, time.UTC)
+	p, err := DiffTimes(a, b)
+	if err != nil {
+		t.Error(err)
+	}
+	correct := Period{Weeks: 1, Days: 2, Hours: 2, Minutes: 30, Nanoseconds: 5}
+	if p != correct {
+		t.Errorf("[computed: %+v] != [correct: %+v]", p, correct)
+	}
+	p, _ = DiffTimes(b, a)
+	if p != correct.Negate() {
+		t.Errorf("[computed: %+v] != [correct: %+v]", p, correct.Negate())
+	}
+	// the period can be added back
+	end, _ := AddTime(a, correct)
+	if !end.Equal(b) {
+		t.Errorf("[computed: %v] != [correct: %v]", end, b)
+	}
+
+	// more than a time.Duration can hold
+	a = time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC)
+	b = time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+	p, err = DiffTimes(a, b)
+	if err != nil {
+		t.Error(err)
+	}
+	correct = Period{Weeks: 22123, Days: 2, Nanoseconds: 500}
+	if p != correct {
+		t.Errorf("[computed: %+v] != [correct: %+v]", p, correct)
+	}
+	if end, _ = AddTime(a, p); !end.Equal(b) {
+		t.Errorf("[computed: %v] != [correct: %v]", end, b)
+	}
+	p, _ = DiffTimes(a, b, WithUnits("hours", "nanoseconds"))
+	if correct = (Period{Hours: 3716712, Nanoseconds: 500}); p != correct {
+		t.Errorf("[computed: %+v] != [correct: %+v]", p, correct)
+	}
+	if _, err = DiffTimes(a, b, WithUnits("nanoseconds")); !errors.Is(err, ErrRangeOverflow) {
+		t.Errorf("[computed: %v] is not: [correct: %v]", err, ErrRangeOverflow)
+	}
+}
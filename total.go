@@ -0,0 +1,117 @@
+package dtdiff
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// fixedUnits maps the units with a fixed length to their size
+var fixedUnits = map[string]time.Duration{
+	"week":        7 * 24 * time.Hour,
+	"day":         24 * time.Hour,
+	"hour":        time.Hour,
+	"minute":      time.Minute,
+	"second":      time.Second,
+	"millisecond": time.Millisecond,
+	"microsecond": time.Microsecond,
+	"nanosecond":  time.Nanosecond,
+}
+
+// briefUnits maps brief unit names to their long singular form
+var briefUnits = map[string]string{
+	"Y": "year", "M": "month", "W": "week", "D": "day",
+	"h": "hour", "m": "minute", "s": "second",
+	"ms": "millisecond", "us": "microsecond", "µs": "microsecond", "ns": "nanosecond",
+}
+
+// parseUnit return the long singular form of a unit such as "hours", "hour" or "h"
+// brief names are case-sensitive since "M" is a month and "m" is a minute
+func parseUnit(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if unit, ok := briefUnits[name]; ok {
+		return unit, nil
+	}
+	unit := removeTrailingS(strings.ToLower(name))
+	if _, ok := fixedUnits[unit]; ok || unit == "year" || unit == "month" {
+		return unit, nil
+	}
+	return "", fmt.Errorf("[parseUnit] Invalid unit: %s", name)
+}
+
+// Total return the difference between start and end expressed in a single unit, such as
+// "hours" or "days", with "precision" digits after the decimal point; the last digit is rounded
+// months and years follow the calendar: whole months are counted the same way as Add and the
+// remainder is divided by the length of the next month, so 2024-04-16 to 2024-05-01 is 0.5 months
+func (dt *DtDiff) Total(unit string, precision int) (string, error) {
+	if precision < 0 {
+		return "", fmt.Errorf("[Total] Invalid precision: %d", precision)
+	}
+	unit, err := parseUnit(unit)
+	if err != nil {
+		return "", err
+	}
+	_, err = dt.dur()
+	if err != nil {
+		return "", err
+	}
+
+	var total *big.Rat
+	if size, ok := fixedUnits[unit]; ok {
+		total = new(big.Rat).SetFrac(exactDiff(dt.startTime, dt.endTime), big.NewInt(int64(size)))
+	} else {
+		total = calendarTotal(dt.startTime, dt.endTime, unit == "year")
+	}
+	return total.FloatString(precision), nil
+}
+
+// exactDiff return the number of nanoseconds from start to end
+// unlike a time.Duration, this is not limited to about 292 years
+func exactDiff(start, end time.Time) *big.Int {
+	nanos := big.NewInt(end.Unix() - start.Unix())
+	nanos.Mul(nanos, big.NewInt(int64(time.Second)))
+	return nanos.Add(nanos, big.NewInt(int64(end.Nanosecond()-start.Nanosecond())))
+}
+
+// calendarTotal return the number of months, or years when "years" is true, from start to end
+// including the fraction of the next month or year that has passed
+func calendarTotal(start, end time.Time, years bool) *big.Rat {
+	end = end.In(start.Location())
+	sign := 1
+	if end.Before(start) {
+		sign = -1
+	}
+	step := func(t time.Time, n int) time.Time {
+		if years {
+			return t.AddDate(sign*n, 0, 0)
+		}
+		return t.AddDate(0, sign*n, 0)
+	}
+	reached := func(t time.Time) bool {
+		if sign == 1 {
+			return !t.After(end)
+		}
+		return !t.Before(end)
+	}
+
+	whole := sign * (end.Year() - start.Year())
+	if !years {
+		whole = sign * ((end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month()))
+	}
+	for whole > 0 && !reached(step(start, whole)) {
+		whole--
+	}
+	anchor := step(start, whole)
+	next := step(start, whole+1)
+
+	total := big.NewRat(int64(whole), 1)
+	span := next.Sub(anchor) * time.Duration(sign)
+	if span > 0 {
+		total.Add(total, big.NewRat(int64(end.Sub(anchor)*time.Duration(sign)), int64(span)))
+	}
+	if sign == -1 {
+		total.Neg(total)
+	}
+	return total
+}
@@ -0,0 +1,52 @@
+package dtdiff
+
+import (
+	"testing"
+)
+
+func testTotal(t *testing.T, start, end, unit string, precision int, correct string) {
+	dt := New(start, end)
+	total, err := dt.Total(unit, precision)
+	if err != nil {
+		t.Error(err)
+	}
+	if total != correct {
+		t.Errorf("[start: %v] [end: %v] [unit: %v] [computed: %v] != [correct: %v]", start, end, unit, total, correct)
+	}
+}
+
+func TestTotalFixedUnits(t *testing.T) {
+	start := "2024-06-07 08:00:00"
+	end := "2024-06-09 10:30:00"
+	testTotal(t, start, end, "hours", 0, "51")
+	testTotal(t, start, end, "hours", 2, "50.50")
+	testTotal(t, start, end, "h", 1, "50.5")
+	testTotal(t, start, end, "minutes", 0, "3030")
+	testTotal(t, start, end, "days", 3, "2.104")
+	testTotal(t, start, end, "W", 4, "0.3006")
+	testTotal(t, start, end, "nanoseconds", 0, "181800000000000")
+	testTotal(t, end, start, "seconds", 0, "-181800")
+
+	// more than a time.Duration can hold
+	testTotal(t, "1600-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "days", 0, "154863")
+	testTotal(t, "2024-01-01T00:00:00.5Z", "1600-01-01T00:00:00Z", "seconds", 1, "-13380163200.5")
+	testTotal(t, "0001-01-01T00:00:00Z", "9999-12-31T00:00:00Z", "ns", 0, "315537811200000000000")
+}
+
+func TestTotalCalendarUnits(t *testing.T) {
+	testTotal(t, "2024-04-16", "2024-05-01", "months", 2, "0.50")
+	testTotal(t, "2024-01-31", "2024-03-31", "M", 0, "2")
+	testTotal(t, "2023-07-01", "2025-01-01", "years", 1, "1.5")
+	testTotal(t, "2025-01-01", "2023-07-01", "years", 2, "-1.50")
+	testTotal(t, "2024-02-29", "2025-02-28", "Y", 3, "0.997")
+}
+
+func TestTotalInvalid(t *testing.T) {
+	dt := New("2024-01-01", "2024-02-01")
+	if _, err := dt.Total("fortnights", 0); err == nil {
+		t.Error("expected an error for an invalid unit")
+	}
+	if _, err := dt.Total("days", -1); err == nil {
+		t.Error("expected an error for a negative precision")
+	}
+}